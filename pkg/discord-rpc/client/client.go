@@ -1,97 +1,232 @@
 package client
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"os"
+	"sync"
 
 	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/ipc"
 )
 
-var logged bool
+// ErrNotLoggedIn is returned by commands sent before Login succeeded or after Close
+var ErrNotLoggedIn = errors.New("discord-rpc: not logged in")
 
-// Login sends a handshake in the socket and returns an error or nil
-func Login(clientid string) error {
-	if !logged {
-		payload, err := json.Marshal(Handshake{"1", clientid})
-		if err != nil {
-			return err
-		}
+// Dialer opens the Discord IPC socket at path
+type Dialer func(ctx context.Context, path string) (net.Conn, error)
 
-		err = ipc.OpenSocket()
-		if err != nil {
-			return err
-		}
+// Option configures a Client
+type Option func(*Client)
 
-		resp, err := ipc.Send(0, string(payload))
-		if err != nil {
-			return fmt.Errorf("failed to send handshake: %w", err)
-		}
+// WithClientID sets the application ID of the client
+func WithClientID(clientID string) Option {
+	return func(c *Client) {
+		c.clientID = clientID
+	}
+}
 
-		var response Response
-		if err := json.Unmarshal([]byte(resp), &response); err != nil {
-			return fmt.Errorf("failed to parse response: %w", err)
-		}
+// WithSocketPath sets the path of the IPC socket instead of trying the ones ipc.Candidates finds
+func WithSocketPath(path string) Option {
+	return func(c *Client) {
+		c.socketPath = path
+	}
+}
 
-		if response.Event == "ERROR" {
-			return fmt.Errorf("discord error: %v", response.Data)
-		}
+// WithDialer sets how the IPC socket is opened, for example to connect to a fake server in tests
+func WithDialer(dialer Dialer) Option {
+	return func(c *Client) {
+		c.dial = dialer
+	}
+}
 
-		var handshakeResp HandshakeResponse
-		if respData, err := json.Marshal(response.Data); err == nil {
-			if err := json.Unmarshal(respData, &handshakeResp); err != nil {
-				return fmt.Errorf("failed to parse handshake response: %w", err)
-			}
-		}
+// WithLogger sets the logger for connection events. Nothing is logged by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
 	}
-	logged = true
+}
+
+// Client is a rich presence connection to the Discord client. It owns its
+// socket and is safe for concurrent use
+type Client struct {
+	clientID   string
+	socketPath string
+	dial       Dialer
+	logger     *slog.Logger
 
-	return nil
+	// loginMu serializes Login and Close so only one connection is opened
+	loginMu sync.Mutex
+
+	mu            sync.Mutex
+	session       *session
+	handlers      map[string][]*handlerEntry
+	subscriptions map[string]bool
+	activity      *PayloadActivity
 }
 
-func Logout() {
-	logged = false
+// New creates a client. It does not connect until Login is called
+func New(options ...Option) *Client {
+	c := &Client{
+		dial:   ipc.Dial,
+		logger: slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+// current returns the open session or ErrNotLoggedIn
+func (c *Client) current() (*session, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.session == nil || c.session.closed() {
+		return nil, ErrNotLoggedIn
+	}
+	return c.session, nil
+}
+
+// Login connects to Discord, sends the handshake and returns the READY data
+// with the logged in user. If the client is already logged in, it returns the
+// READY data of that connection. After Login, a goroutine reads the responses
+// and events until the connection closes
+func (c *Client) Login(ctx context.Context) (*HandshakeResponse, error) {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
 
-	err := ipc.CloseSocket()
+	if s, err := c.current(); err == nil {
+		return s.ready, nil
+	}
+	if c.clientID == "" {
+		return nil, errors.New("discord-rpc: missing client ID")
+	}
+
+	paths := ipc.Candidates()
+	if c.socketPath != "" {
+		paths = []string{c.socketPath}
+	}
+	sock, path, err := ipc.DialFirst(ctx, paths, c.dial)
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSession(ipc.NewConn(sock), c.logger)
+	ready, err := s.handshake(ctx, c.clientID)
 	if err != nil {
-		panic(err)
+		sock.Close()
+		var discordErr *Error
+		var closeErr *CloseError
+		if errors.As(err, &discordErr) || errors.As(err, &closeErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to send handshake: %w", err)
+	}
+
+	s.ready = &HandshakeResponse{}
+	if err := json.Unmarshal(ready.Data, s.ready); err != nil {
+		sock.Close()
+		return nil, fmt.Errorf("failed to parse handshake response: %w", err)
 	}
+
+	s.dispatch(Event{Name: ready.Event, Data: ready.Data})
+	go s.readLoop()
+	go c.dispatchLoop(s.events)
+
+	c.mu.Lock()
+	c.session = s
+	c.mu.Unlock()
+	c.logger.Info("discord-rpc: logged in", "path", path, "user", s.ready.User.Username)
+	return s.ready, nil
 }
 
-func SetActivity(activity PayloadActivity) error {
-	if !logged {
+// Ready returns the READY data of the current connection, or nil if the
+// client is not logged in
+func (c *Client) Ready() *HandshakeResponse {
+	s, err := c.current()
+	if err != nil {
 		return nil
 	}
+	return s.ready
+}
 
-	payload, err := json.Marshal(Frame{
-		Cmd: "SET_ACTIVITY",
-		Args: Args{
-			Pid:      os.Getpid(),
-			Activity: &activity,
-		},
-		Nonce: getNonce(),
-	})
+// SetActivity validates the activity and sets it as the rich presence of the
+// user. Nothing is sent if the activity breaks Discord's limits; the error
+// then holds a *ValidationError for every problem
+func (c *Client) SetActivity(ctx context.Context, activity Activity) error {
+	payload := mapActivity(&activity)
+	if err := payload.Validate(); err != nil {
+		return err
+	}
+	return c.setActivity(ctx, payload)
+}
 
-	if err != nil {
+// SetActivityPayload validates the activity like SetActivity and sets it as
+// the rich presence of the user. Timestamps are Unix milliseconds
+func (c *Client) SetActivityPayload(ctx context.Context, activity PayloadActivity) error {
+	if err := activity.Validate(); err != nil {
 		return err
 	}
+	return c.setActivity(ctx, &activity)
+}
+
+// setActivity sends the activity. The client remembers it even when it is not
+// logged in, and Supervise sends it again after every reconnect
+func (c *Client) setActivity(ctx context.Context, activity *PayloadActivity) error {
+	c.mu.Lock()
+	c.activity = activity
+	c.mu.Unlock()
 
-	resp, err := ipc.Send(1, string(payload))
+	s, err := c.current()
 	if err != nil {
-		return fmt.Errorf("failed to send activity: %w", err)
+		return err
 	}
 
-	var response Response
-	if err := json.Unmarshal([]byte(resp), &response); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+	_, err = s.call(ctx, setActivityCommand(activity))
+	var discordErr *Error
+	if err != nil && !errors.As(err, &discordErr) {
+		return fmt.Errorf("failed to send activity: %w", err)
 	}
+	return err
+}
 
-	if response.Event == "ERROR" {
-		return fmt.Errorf("discord error: %v", response.Data)
+// ClearActivity removes the rich presence of the user. Supervise no longer
+// restores an activity after a reconnect
+func (c *Client) ClearActivity(ctx context.Context) error {
+	return c.setActivity(ctx, nil)
+}
+
+// setActivityCommand builds the SET_ACTIVITY command; a nil activity clears it
+func setActivityCommand(activity *PayloadActivity) command {
+	return command{
+		Cmd: "SET_ACTIVITY",
+		Args: Args{
+			Pid:      os.Getpid(),
+			Activity: activity,
+		},
 	}
+}
+
+// Close closes the connection. The client can log in again afterwards
+func (c *Client) Close() error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	c.mu.Lock()
+	s := c.session
+	c.session = nil
+	c.mu.Unlock()
 
-	return nil
+	if s == nil {
+		return nil
+	}
+	err := s.close()
+	c.logger.Info("discord-rpc: closed")
+	return err
 }
 
 func getNonce() string {
@@ -105,3 +240,79 @@ func getNonce() string {
 
 	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:])
 }
+
+var (
+	defaultMu     sync.Mutex
+	defaultClient *Client
+)
+
+// Login sends a handshake in the socket and returns an error or nil.
+// It uses a default client for clientid. A call with another clientid
+// closes the default client and replaces it with one for the new ID
+func Login(clientid string) error {
+	defaultMu.Lock()
+	previous := defaultClient
+	if defaultClient == nil || defaultClient.clientID != clientid {
+		defaultClient = New(WithClientID(clientid))
+	}
+	c := defaultClient
+	defaultMu.Unlock()
+
+	if previous != nil && previous != c {
+		previous.Close()
+	}
+	_, err := c.Login(context.Background())
+	return err
+}
+
+// Logout closes the connection of the default client
+func Logout() {
+	defaultMu.Lock()
+	c := defaultClient
+	defaultClient = nil
+	defaultMu.Unlock()
+
+	if c != nil {
+		c.Close()
+	}
+}
+
+// SetActivity sets the rich presence through the default client. It does
+// nothing before Login
+func SetActivity(activity Activity) error {
+	return withDefault(func(c *Client) error {
+		return c.SetActivity(context.Background(), activity)
+	})
+}
+
+// SetActivityPayload sets the rich presence through the default client. It
+// does nothing before Login
+func SetActivityPayload(activity PayloadActivity) error {
+	return withDefault(func(c *Client) error {
+		return c.SetActivityPayload(context.Background(), activity)
+	})
+}
+
+// ClearActivity removes the rich presence through the default client. It
+// does nothing before Login
+func ClearActivity() error {
+	return withDefault(func(c *Client) error {
+		return c.ClearActivity(context.Background())
+	})
+}
+
+// withDefault runs fn with the default client, treating a missing login as success
+func withDefault(fn func(c *Client) error) error {
+	defaultMu.Lock()
+	c := defaultClient
+	defaultMu.Unlock()
+
+	if c == nil {
+		return nil
+	}
+	err := fn(c)
+	if errors.Is(err, ErrNotLoggedIn) {
+		return nil
+	}
+	return err
+}
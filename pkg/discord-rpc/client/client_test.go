@@ -0,0 +1,366 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/client"
+	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/ipc"
+)
+
+// fakeDiscord is the Discord end of a client connection
+type fakeDiscord struct {
+	t    *testing.T
+	conn net.Conn
+}
+
+type message struct {
+	Cmd   string          `json:"cmd"`
+	Args  json.RawMessage `json:"args"`
+	Event string          `json:"evt"`
+	Nonce string          `json:"nonce"`
+}
+
+func newFakeDiscord(t *testing.T, options ...client.Option) (*client.Client, *fakeDiscord) {
+	clientSide, discordSide := net.Pipe()
+	options = append([]client.Option{
+		client.WithClientID("123"),
+		client.WithSocketPath("fake"),
+		client.WithDialer(func(ctx context.Context, path string) (net.Conn, error) {
+			return clientSide, nil
+		}),
+	}, options...)
+	c := client.New(options...)
+	t.Cleanup(func() {
+		discordSide.Close()
+		c.Close()
+	})
+	return c, &fakeDiscord{t, discordSide}
+}
+
+func (d *fakeDiscord) read() (ipc.Opcode, message) {
+	opcode, payload, err := ipc.ReadFrame(d.conn)
+	if err != nil {
+		d.t.Errorf("ReadFrame() error = %v", err)
+		return 0, message{}
+	}
+	var m message
+	if err := json.Unmarshal(payload, &m); err != nil {
+		d.t.Errorf("invalid frame %s: %v", payload, err)
+	}
+	return opcode, m
+}
+
+func (d *fakeDiscord) send(opcode ipc.Opcode, v any) {
+	payload, _ := json.Marshal(v)
+	if err := ipc.WriteFrame(d.conn, opcode, payload); err != nil {
+		d.t.Errorf("WriteFrame() error = %v", err)
+	}
+}
+
+func (d *fakeDiscord) reply(m message, data any) {
+	d.send(1, map[string]any{"cmd": m.Cmd, "data": data, "evt": nil, "nonce": m.Nonce})
+}
+
+func (d *fakeDiscord) dispatch(event string, data any) {
+	d.send(1, map[string]any{"cmd": "DISPATCH", "data": data, "evt": event})
+}
+
+// handshake answers the handshake with READY
+func (d *fakeDiscord) handshake() {
+	if opcode, _ := d.read(); opcode != 0 {
+		d.t.Errorf("handshake opcode = %d, want 0", opcode)
+	}
+	d.dispatch(client.EventReady, map[string]any{
+		"v":      1,
+		"config": map[string]any{"cdn_host": "cdn.discordapp.com", "api_endpoint": "//discord.com/api", "environment": "production"},
+		"user":   map[string]any{"id": "80351110224678912", "username": "steve", "discriminator": "0", "avatar": "a_abc"},
+	})
+}
+
+// login logs the client in
+func (d *fakeDiscord) login(c *client.Client) *client.HandshakeResponse {
+	go d.handshake()
+	ready, err := c.Login(context.Background())
+	if err != nil {
+		d.t.Fatalf("Login() error = %v", err)
+	}
+	return ready
+}
+
+func wait[T any](t *testing.T, ch <-chan T) T {
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+		panic("unreachable")
+	}
+}
+
+func TestLoginReady(t *testing.T) {
+	c, d := newFakeDiscord(t)
+	if c.Ready() != nil {
+		t.Error("Ready() before Login is not nil")
+	}
+	ready := d.login(c)
+	if ready.User.DisplayName() != "steve" || ready.Config.CDNHost != "cdn.discordapp.com" {
+		t.Errorf("Login() = %+v", ready)
+	}
+	if url := ready.AvatarURL(128); url != "https://cdn.discordapp.com/avatars/80351110224678912/a_abc.gif?size=128" {
+		t.Errorf("AvatarURL() = %s", url)
+	}
+
+	again, err := c.Login(context.Background())
+	if err != nil || again != ready || c.Ready() != ready {
+		t.Errorf("Login() when logged in = %p, %v, want %p", again, err, ready)
+	}
+}
+
+func TestEventsBetweenResponses(t *testing.T) {
+	c, d := newFakeDiscord(t)
+	ready := make(chan client.Event, 1)
+	joins := make(chan string, 1)
+	c.On(client.EventReady, func(e client.Event) { ready <- e })
+	c.On(client.EventActivityJoin, func(e client.Event) {
+		var secret client.ActivitySecret
+		e.Decode(&secret)
+		joins <- secret.Secret
+	})
+	d.login(c)
+	wait(t, ready)
+
+	go func() {
+		_, m := d.read()
+		if m.Cmd != "SET_ACTIVITY" {
+			t.Errorf("cmd = %s", m.Cmd)
+		}
+		// An event arriving before the response must not be taken for it
+		d.dispatch(client.EventActivityJoin, map[string]any{"secret": "mc.example.com:25565"})
+		d.reply(m, map[string]any{"details": "Playing"})
+	}()
+	if err := c.SetActivityPayload(context.Background(), client.PayloadActivity{Details: "Playing"}); err != nil {
+		t.Fatalf("SetActivity() error = %v", err)
+	}
+	if secret := wait(t, joins); secret != "mc.example.com:25565" {
+		t.Errorf("ACTIVITY_JOIN secret = %q", secret)
+	}
+}
+
+func TestResponsesByNonce(t *testing.T) {
+	c, d := newFakeDiscord(t)
+	d.login(c)
+
+	go func() {
+		_, first := d.read()
+		_, second := d.read()
+		for _, m := range []message{second, first} {
+			var args struct {
+				Activity client.PayloadActivity `json:"activity"`
+			}
+			json.Unmarshal(m.Args, &args)
+			if args.Activity.Details == "fail" {
+				d.send(1, map[string]any{"cmd": m.Cmd, "data": map[string]any{"code": 4000, "message": "Invalid payload"}, "evt": "ERROR", "nonce": m.Nonce})
+			} else {
+				d.reply(m, nil)
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	errs := map[string]error{}
+	var mu sync.Mutex
+	for _, details := range []string{"ok", "fail"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.SetActivityPayload(context.Background(), client.PayloadActivity{Details: details})
+			mu.Lock()
+			errs[details] = err
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	var discordErr *client.Error
+	if errs["ok"] != nil {
+		t.Errorf("SetActivity(ok) error = %v", errs["ok"])
+	}
+	if !errors.As(errs["fail"], &discordErr) || discordErr.Code != 4000 {
+		t.Errorf("SetActivity(fail) error = %v, want code 4000", errs["fail"])
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	c, d := newFakeDiscord(t)
+	d.login(c)
+	requests := make(chan string, 4)
+	c.On(client.EventActivityJoinRequest, func(e client.Event) { requests <- string(e.Data) })
+
+	go func() {
+		_, m := d.read()
+		if m.Cmd != "SUBSCRIBE" || m.Event != client.EventActivityJoinRequest {
+			t.Errorf("subscribe = %+v", m)
+		}
+		d.reply(m, map[string]any{"evt": m.Event})
+		d.dispatch(client.EventActivityJoinRequest, map[string]any{"user": map[string]any{"id": "1"}})
+	}()
+	if err := c.Subscribe(context.Background(), client.EventActivityJoinRequest); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if data := wait(t, requests); data != `{"user":{"id":"1"}}` {
+		t.Errorf("ACTIVITY_JOIN_REQUEST data = %s", data)
+	}
+
+	go func() {
+		_, m := d.read()
+		if m.Cmd != "UNSUBSCRIBE" || m.Event != client.EventActivityJoinRequest {
+			t.Errorf("unsubscribe = %+v", m)
+		}
+		d.reply(m, map[string]any{"evt": m.Event})
+	}()
+	if err := c.Unsubscribe(context.Background(), client.EventActivityJoinRequest); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+}
+
+func TestConnectionLost(t *testing.T) {
+	c, d := newFakeDiscord(t)
+	d.login(c)
+
+	go func() {
+		d.read()
+		d.conn.Close()
+	}()
+	err := c.SetActivityPayload(context.Background(), client.PayloadActivity{Details: "Playing"})
+	if !errors.Is(err, client.ErrConnectionClosed) {
+		t.Errorf("SetActivity() error = %v, want ErrConnectionClosed", err)
+	}
+	if err := c.SetActivityPayload(context.Background(), client.PayloadActivity{}); !errors.Is(err, client.ErrNotLoggedIn) {
+		t.Errorf("SetActivity() after close error = %v, want ErrNotLoggedIn", err)
+	}
+}
+
+func TestCommandContext(t *testing.T) {
+	c, d := newFakeDiscord(t)
+	d.login(c)
+
+	go d.read()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.SetActivityPayload(ctx, client.PayloadActivity{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SetActivity() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestPing(t *testing.T) {
+	c, d := newFakeDiscord(t)
+	d.login(c)
+
+	if err := ipc.WriteFrame(d.conn, ipc.OpPing, []byte(`{"seq":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	opcode, payload, err := ipc.ReadFrame(d.conn)
+	if err != nil || opcode != ipc.OpPong || string(payload) != `{"seq":1}` {
+		t.Errorf("reply to PING = %v, %s, %v, want PONG with the same payload", opcode, payload, err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	c, d := newFakeDiscord(t)
+	d.login(c)
+
+	closed := make(chan ipc.Opcode, 1)
+	go func() {
+		opcode, _, _ := ipc.ReadFrame(d.conn)
+		closed <- opcode
+	}()
+	if err := c.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if opcode := wait(t, closed); opcode != ipc.OpClose {
+		t.Errorf("Close() sent %v, want CLOSE", opcode)
+	}
+}
+
+func TestCloseFromDiscord(t *testing.T) {
+	tests := []struct {
+		name   string
+		login  bool
+		reason string
+		code   int
+	}{
+		{name: "Invalid client ID", reason: `{"code":4000,"message":"Invalid Client ID"}`, code: client.CloseInvalidClientID},
+		{name: "During a command", login: true, reason: `{"code":4002,"message":"Rate limited"}`, code: client.CloseRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, d := newFakeDiscord(t)
+			if tt.login {
+				d.login(c)
+			}
+			go func() {
+				d.read()
+				ipc.WriteFrame(d.conn, ipc.OpClose, []byte(tt.reason))
+			}()
+
+			var err error
+			if tt.login {
+				err = c.SetActivityPayload(context.Background(), client.PayloadActivity{})
+				if !errors.Is(err, client.ErrConnectionClosed) {
+					t.Errorf("error = %v, want ErrConnectionClosed", err)
+				}
+			} else {
+				_, err = c.Login(context.Background())
+			}
+			var closeErr *client.CloseError
+			if !errors.As(err, &closeErr) || closeErr.Code != tt.code {
+				t.Errorf("error = %v, want CloseError %d", err, tt.code)
+			}
+		})
+	}
+}
+
+func decode(t *testing.T, data json.RawMessage, v any) {
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Errorf("invalid JSON %s: %v", data, err)
+	}
+}
+
+func TestSetActivityPartyWithoutSize(t *testing.T) {
+	c, d := newFakeDiscord(t)
+	d.login(c)
+
+	parties := make(chan map[string]json.RawMessage, 2)
+	go func() {
+		for range 2 {
+			_, m := d.read()
+			var args struct {
+				Activity struct {
+					Party map[string]json.RawMessage `json:"party"`
+				} `json:"activity"`
+			}
+			decode(t, m.Args, &args)
+			parties <- args.Activity.Party
+			d.reply(m, nil)
+		}
+	}()
+	for _, party := range []*client.Party{{ID: "p"}, {ID: "p", Players: 1, MaxPlayers: 4}} {
+		if err := c.SetActivity(context.Background(), client.Activity{Details: "Playing", Party: party}); err != nil {
+			t.Fatalf("SetActivity() error = %v", err)
+		}
+	}
+	// Discord rejects "size":[0,0], so a party without a size must leave it out
+	if party := wait(t, parties); party["size"] != nil || string(party["id"]) != `"p"` {
+		t.Errorf("party without a size = %s", party)
+	}
+	if party := wait(t, parties); string(party["size"]) != "[1,4]" {
+		t.Errorf("party size = %s, want [1,4]", party["size"])
+	}
+}
@@ -0,0 +1,94 @@
+//go:build !windows
+// +build !windows
+
+package client_test
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/client"
+	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/ipc"
+)
+
+// handshakes answers every connection to the socket with READY and reports
+// the client ID of its handshake, and whether it was closed
+type handshakes struct {
+	ids    chan string
+	closed chan string
+}
+
+func listenDiscord(t *testing.T) *handshakes {
+	// Unix socket paths are short, so t.TempDir() may be too long
+	dir, err := os.MkdirTemp("", "discord")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	listener, err := net.Listen("unix", filepath.Join(dir, "discord-ipc-0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	h := &handshakes{ids: make(chan string, 4), closed: make(chan string, 4)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, payload, err := ipc.ReadFrame(conn)
+				if err != nil {
+					return
+				}
+				var handshake struct {
+					ClientID string `json:"client_id"`
+				}
+				json.Unmarshal(payload, &handshake)
+				ready, _ := json.Marshal(map[string]any{"cmd": "DISPATCH", "evt": client.EventReady, "data": map[string]any{"v": 1, "user": map[string]any{"id": "1", "username": "steve"}}})
+				ipc.WriteFrame(conn, 1, ready)
+				h.ids <- handshake.ClientID
+				for {
+					if _, _, err := ipc.ReadFrame(conn); err != nil {
+						h.closed <- handshake.ClientID
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return h
+}
+
+func TestLoginKeysDefaultClientOnID(t *testing.T) {
+	h := listenDiscord(t)
+	defer client.Logout()
+
+	if err := client.Login("111"); err != nil {
+		t.Fatalf("Login(111) error = %v", err)
+	}
+	if id := wait(t, h.ids); id != "111" {
+		t.Errorf("handshake client ID = %s, want 111", id)
+	}
+	if err := client.Login("111"); err != nil {
+		t.Fatalf("second Login(111) error = %v", err)
+	}
+
+	// Another application gets its own connection
+	if err := client.Login("222"); err != nil {
+		t.Fatalf("Login(222) error = %v", err)
+	}
+	if id := wait(t, h.ids); id != "222" {
+		t.Errorf("handshake client ID = %s, want 222 on a new connection", id)
+	}
+	if id := wait(t, h.closed); id != "111" {
+		t.Errorf("closed connection of %s, want 111", id)
+	}
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// Events Discord dispatches. READY and ERROR are always sent; the others need Subscribe
+const (
+	EventReady               = "READY"
+	EventError               = "ERROR"
+	EventActivityJoin        = "ACTIVITY_JOIN"
+	EventActivitySpectate    = "ACTIVITY_SPECTATE"
+	EventActivityJoinRequest = "ACTIVITY_JOIN_REQUEST"
+)
+
+// Event is an event Discord dispatched on its own rather than in reply to a command
+type Event struct {
+	// Name is the event, such as EventActivityJoin
+	Name string
+	// Data is the JSON payload of the event
+	Data json.RawMessage
+}
+
+// Decode unmarshals the payload of the event into v
+func (e Event) Decode(v any) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// ActivitySecret is the payload of the ACTIVITY_JOIN and ACTIVITY_SPECTATE events
+type ActivitySecret struct {
+	Secret string `json:"secret"`
+}
+
+// EventHandler handles an event. Handlers run one at a time on a goroutine
+// of their own, so they may send commands
+type EventHandler func(Event)
+
+type handlerEntry struct {
+	handle EventHandler
+}
+
+// On registers a handler for the event and returns a function that removes it.
+// Handlers stay registered when the connection is closed and opened again
+func (c *Client) On(event string, handler EventHandler) (remove func()) {
+	entry := &handlerEntry{handler}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.handlers == nil {
+		c.handlers = map[string][]*handlerEntry{}
+	}
+	c.handlers[event] = append(c.handlers[event], entry)
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		entries := c.handlers[event]
+		for i, e := range entries {
+			if e == entry {
+				c.handlers[event] = append(entries[:i:i], entries[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// dispatchLoop runs the handlers for the events of a session until it closes
+func (c *Client) dispatchLoop(events <-chan Event) {
+	for event := range events {
+		c.mu.Lock()
+		entries := c.handlers[event.Name]
+		c.mu.Unlock()
+
+		for _, entry := range entries {
+			entry.handle(event)
+		}
+	}
+}
+
+// Subscribe asks Discord to dispatch the event, such as EventActivityJoin.
+// Register a handler for it with On. The client remembers the subscription
+// even when it is not logged in, and Supervise sends it again after every
+// reconnect. Only a subscription Discord rejects is forgotten
+func (c *Client) Subscribe(ctx context.Context, event string) error {
+	c.mu.Lock()
+	if c.subscriptions == nil {
+		c.subscriptions = map[string]bool{}
+	}
+	c.subscriptions[event] = true
+	c.mu.Unlock()
+
+	s, err := c.current()
+	if err != nil {
+		return err
+	}
+	_, err = s.call(ctx, command{Cmd: "SUBSCRIBE", Args: struct{}{}, Event: event})
+	var discordErr *Error
+	if errors.As(err, &discordErr) {
+		c.mu.Lock()
+		delete(c.subscriptions, event)
+		c.mu.Unlock()
+	}
+	return err
+}
+
+// Unsubscribe asks Discord to stop dispatching the event. The client forgets
+// the subscription even when it is not logged in
+func (c *Client) Unsubscribe(ctx context.Context, event string) error {
+	c.mu.Lock()
+	delete(c.subscriptions, event)
+	c.mu.Unlock()
+
+	s, err := c.current()
+	if err != nil {
+		return err
+	}
+	_, err = s.call(ctx, command{Cmd: "UNSUBSCRIBE", Args: struct{}{}, Event: event})
+	return err
+}
@@ -6,18 +6,32 @@ import (
 
 // Activity holds the data for discord rich presence
 type Activity struct {
+	// Overrides the application name, so Discord shows "Playing <Name>"
+	Name string
+	// Which field the member list shows, one of the StatusDisplayType values
+	StatusDisplayType int
 	// What the player is currently doing
 	Details string
+	// URL opened when clicking the details
+	DetailsURL string
 	// The user's current party status
 	State string
+	// URL opened when clicking the state
+	StateURL string
 	// The id for a large asset of the activity, usually a snowflake
 	LargeImage string
 	// Text displayed when hovering over the large image of the activity
 	LargeText string
+	// URL opened when clicking the large image
+	LargeURL string
 	// The id for a small asset of the activity, usually a snowflake
 	SmallImage string
 	// Text displayed when hovering over the small image of the activity
 	SmallText string
+	// URL opened when clicking the small image
+	SmallURL string
+	// Whether the activity is an instanced game session, such as a match
+	Instance bool
 	// Information for the current party of the player
 	Party *Party
 	// Unix timestamps for start and/or end of the game
@@ -46,11 +60,12 @@ type Party struct {
 	MaxPlayers int
 }
 
-// Timestamps holds unix timestamps for start and/or end of the game
+// Timestamps holds the start and/or end of the game. They are sent as unix
+// time in milliseconds
 type Timestamps struct {
-	// unix time (in milliseconds) of when the activity started
+	// When the activity started; Discord shows the elapsed time
 	Start *time.Time
-	// unix time (in milliseconds) of when the activity ends
+	// When the activity ends; Discord shows the remaining time. Only sent with Start
 	End *time.Time
 }
 
@@ -66,14 +81,21 @@ type Secrets struct {
 
 func mapActivity(activity *Activity) *PayloadActivity {
 	final := &PayloadActivity{
-		Details: activity.Details,
-		State:   activity.State,
+		Name:              activity.Name,
+		StatusDisplayType: activity.StatusDisplayType,
+		Details:           activity.Details,
+		DetailsURL:        activity.DetailsURL,
+		State:             activity.State,
+		StateURL:          activity.StateURL,
 		Assets: PayloadAssets{
 			LargeImage: activity.LargeImage,
 			LargeText:  activity.LargeText,
+			LargeURL:   activity.LargeURL,
 			SmallImage: activity.SmallImage,
 			SmallText:  activity.SmallText,
+			SmallURL:   activity.SmallURL,
 		},
+		Instance: activity.Instance,
 	}
 
 	if activity.Timestamps != nil && activity.Timestamps.Start != nil {
@@ -88,9 +110,9 @@ func mapActivity(activity *Activity) *PayloadActivity {
 	}
 
 	if activity.Party != nil {
-		final.Party = &PayloadParty{
-			ID:   activity.Party.ID,
-			Size: [2]int{activity.Party.Players, activity.Party.MaxPlayers},
+		final.Party = &PayloadParty{ID: activity.Party.ID}
+		if activity.Party.Players != 0 || activity.Party.MaxPlayers != 0 {
+			final.Party.Size = &[2]int{activity.Party.Players, activity.Party.MaxPlayers}
 		}
 	}
 
@@ -104,6 +126,9 @@ func mapActivity(activity *Activity) *PayloadActivity {
 
 	if len(activity.Buttons) > 0 {
 		for _, btn := range activity.Buttons {
+			if btn == nil {
+				continue
+			}
 			final.Buttons = append(final.Buttons, &PayloadButton{
 				Label: btn.Label,
 				Url:   btn.Url,
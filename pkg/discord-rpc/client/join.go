@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidJoinSecret is returned by ParseJoinSecret for secrets JoinSecret did not create
+var ErrInvalidJoinSecret = errors.New("discord-rpc: not a server join secret")
+
+// joinSecretPrefix marks join secrets that carry a Minecraft server address
+const joinSecretPrefix = "mc:"
+
+// joinTimeout bounds the commands HandleJoins sends to answer a join request
+const joinTimeout = 5 * time.Second
+
+// JoinRequest is the payload of the ACTIVITY_JOIN_REQUEST event
+type JoinRequest struct {
+	// User is the user asking to join the party
+	User User `json:"user"`
+}
+
+// userArgs are the arguments of the commands answering a join request
+type userArgs struct {
+	UserID string `json:"user_id"`
+}
+
+// SendActivityJoinInvite accepts the join request of the user. Discord then
+// sends them the join secret in an ACTIVITY_JOIN event
+func (c *Client) SendActivityJoinInvite(ctx context.Context, userID string) error {
+	return c.answerJoinRequest(ctx, "SEND_ACTIVITY_JOIN_INVITE", userID)
+}
+
+// CloseActivityRequest rejects the join request of the user
+func (c *Client) CloseActivityRequest(ctx context.Context, userID string) error {
+	return c.answerJoinRequest(ctx, "CLOSE_ACTIVITY_REQUEST", userID)
+}
+
+func (c *Client) answerJoinRequest(ctx context.Context, cmd, userID string) error {
+	s, err := c.current()
+	if err != nil {
+		return err
+	}
+	_, err = s.call(ctx, command{Cmd: cmd, Args: userArgs{userID}})
+	return err
+}
+
+// OnJoinRequest registers a handler for ACTIVITY_JOIN_REQUEST events like On.
+// Events with an invalid payload are logged and skipped
+func (c *Client) OnJoinRequest(handler func(JoinRequest)) (remove func()) {
+	return c.On(EventActivityJoinRequest, func(e Event) {
+		var request JoinRequest
+		if err := e.Decode(&request); err != nil {
+			c.logger.Warn("discord-rpc: invalid join request", "error", err)
+			return
+		}
+		handler(request)
+	})
+}
+
+// JoinSecret encodes a Minecraft server address, such as "mc.example.com:25565",
+// as the join secret of an activity. Discord also needs a party with an ID
+// and a size to show the join button
+func JoinSecret(address string) (string, error) {
+	if address == "" {
+		return "", errors.New("discord-rpc: empty server address")
+	}
+	secret := joinSecretPrefix + base64.RawURLEncoding.EncodeToString([]byte(address))
+	if len(secret) > MaxIDLength {
+		return "", fmt.Errorf("discord-rpc: server address %q is too long for a join secret", address)
+	}
+	return secret, nil
+}
+
+// ParseJoinSecret returns the server address JoinSecret encoded in the secret
+func ParseJoinSecret(secret string) (string, error) {
+	encoded, ok := strings.CutPrefix(secret, joinSecretPrefix)
+	if !ok {
+		return "", ErrInvalidJoinSecret
+	}
+	address, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(address) == 0 {
+		return "", ErrInvalidJoinSecret
+	}
+	return string(address), nil
+}
+
+// JoinOptions configures HandleJoins
+type JoinOptions struct {
+	// Launch is called with the server address of the join secret when the
+	// user joins a party from Discord. It runs on the event goroutine
+	Launch func(address string)
+	// Accept decides whether to invite a user who asks to join. When it is
+	// nil the requests are left for the user to answer in Discord
+	Accept func(JoinRequest) bool
+}
+
+// HandleJoins lets other users join the server of the activity, whose join
+// secret must come from JoinSecret. It subscribes to ACTIVITY_JOIN, and to
+// ACTIVITY_JOIN_REQUEST when options.Accept is set, and returns a function
+// that removes the handlers. It may be called before Discord is running: the
+// subscriptions are remembered and Supervise sends them after every login
+func (c *Client) HandleJoins(ctx context.Context, options JoinOptions) (remove func(), err error) {
+	if options.Launch == nil {
+		return nil, errors.New("discord-rpc: missing launch callback")
+	}
+
+	events := []string{EventActivityJoin}
+	removes := []func(){c.On(EventActivityJoin, func(e Event) {
+		var join ActivitySecret
+		if err := e.Decode(&join); err != nil {
+			c.logger.Warn("discord-rpc: invalid join event", "error", err)
+			return
+		}
+		address, err := ParseJoinSecret(join.Secret)
+		if err != nil {
+			c.logger.Warn("discord-rpc: ignored join", "error", err)
+			return
+		}
+		options.Launch(address)
+	})}
+	if options.Accept != nil {
+		events = append(events, EventActivityJoinRequest)
+		removes = append(removes, c.OnJoinRequest(func(request JoinRequest) {
+			ctx, cancel := context.WithTimeout(context.Background(), joinTimeout)
+			defer cancel()
+			var err error
+			if options.Accept(request) {
+				err = c.SendActivityJoinInvite(ctx, request.User.ID)
+			} else {
+				err = c.CloseActivityRequest(ctx, request.User.ID)
+			}
+			if err != nil {
+				c.logger.Warn("discord-rpc: failed to answer join request", "user", request.User.ID, "error", err)
+			}
+		}))
+	}
+	remove = func() {
+		for _, r := range removes {
+			r()
+		}
+	}
+
+	for _, event := range events {
+		if err := c.Subscribe(ctx, event); err != nil && !errors.Is(err, ErrNotLoggedIn) {
+			remove()
+			return nil, err
+		}
+	}
+	return remove, nil
+}
@@ -0,0 +1,126 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/client"
+)
+
+func TestJoinSecret(t *testing.T) {
+	for _, address := range []string{"mc.hypixel.net", "192.168.1.20:25566", "[::1]:25565"} {
+		secret, err := client.JoinSecret(address)
+		if err != nil {
+			t.Fatalf("JoinSecret(%q) error = %v", address, err)
+		}
+		if parsed, err := client.ParseJoinSecret(secret); err != nil || parsed != address {
+			t.Errorf("ParseJoinSecret(%q) = %q, %v, want %q", secret, parsed, err, address)
+		}
+	}
+
+	if _, err := client.JoinSecret(strings.Repeat("a", 100) + ".com"); err == nil {
+		t.Error("JoinSecret() accepted an address longer than a secret")
+	}
+	for _, secret := range []string{"", "party-secret", "mc:", "mc:%%%"} {
+		if _, err := client.ParseJoinSecret(secret); !errors.Is(err, client.ErrInvalidJoinSecret) {
+			t.Errorf("ParseJoinSecret(%q) error = %v, want ErrInvalidJoinSecret", secret, err)
+		}
+	}
+}
+
+func TestHandleJoins(t *testing.T) {
+	c, d := newFakeDiscord(t)
+	d.login(c)
+	secret, _ := client.JoinSecret("mc.example.com:25565")
+
+	launched := make(chan string, 1)
+	requests := make(chan client.JoinRequest, 1)
+	subscribed := make(chan []string, 1)
+	go func() {
+		var events []string
+		for range 2 {
+			_, m := d.read()
+			events = append(events, m.Cmd+" "+m.Event)
+			d.reply(m, map[string]any{"evt": m.Event})
+		}
+		subscribed <- events
+	}()
+	remove, err := c.HandleJoins(context.Background(), client.JoinOptions{
+		Launch: func(address string) { launched <- address },
+		Accept: func(request client.JoinRequest) bool {
+			requests <- request
+			return request.User.Username == "alex"
+		},
+	})
+	if err != nil {
+		t.Fatalf("HandleJoins() error = %v", err)
+	}
+	defer remove()
+	if events := wait(t, subscribed); strings.Join(events, ",") != "SUBSCRIBE ACTIVITY_JOIN,SUBSCRIBE ACTIVITY_JOIN_REQUEST" {
+		t.Errorf("subscriptions = %v", events)
+	}
+
+	answers := make(chan string, 2)
+	go func() {
+		for _, user := range []string{"alex", "herobrine"} {
+			d.dispatch(client.EventActivityJoinRequest, map[string]any{"user": map[string]any{"id": user + "-id", "username": user}})
+			_, m := d.read()
+			var args struct {
+				UserID string `json:"user_id"`
+			}
+			decode(t, m.Args, &args)
+			answers <- m.Cmd + " " + args.UserID
+			d.reply(m, nil)
+		}
+		d.dispatch(client.EventActivityJoin, map[string]any{"secret": "not-ours"})
+		d.dispatch(client.EventActivityJoin, map[string]any{"secret": secret})
+	}()
+
+	if request := wait(t, requests); request.User.ID != "alex-id" {
+		t.Errorf("join request user = %+v", request.User)
+	}
+	if answer := wait(t, answers); answer != "SEND_ACTIVITY_JOIN_INVITE alex-id" {
+		t.Errorf("answer = %q", answer)
+	}
+	wait(t, requests)
+	if answer := wait(t, answers); answer != "CLOSE_ACTIVITY_REQUEST herobrine-id" {
+		t.Errorf("answer = %q", answer)
+	}
+	if address := wait(t, launched); address != "mc.example.com:25565" {
+		t.Errorf("launched %q, want mc.example.com:25565", address)
+	}
+}
+
+func TestHandleJoinsBeforeLogin(t *testing.T) {
+	c, d := newFakeDiscord(t)
+	secret, _ := client.JoinSecret("mc.example.com")
+
+	// The launcher starts before Discord does
+	launched := make(chan string, 1)
+	remove, err := c.HandleJoins(context.Background(), client.JoinOptions{
+		Launch: func(address string) { launched <- address },
+	})
+	if err != nil {
+		t.Fatalf("HandleJoins() before Login error = %v", err)
+	}
+	defer remove()
+
+	go func() {
+		d.handshake()
+		_, m := d.read()
+		if m.Cmd != "SUBSCRIBE" || m.Event != client.EventActivityJoin {
+			t.Errorf("restored command = %s %s, want SUBSCRIBE ACTIVITY_JOIN", m.Cmd, m.Event)
+		}
+		d.reply(m, map[string]any{"evt": m.Event})
+		d.dispatch(client.EventActivityJoin, map[string]any{"secret": secret})
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Supervise(ctx, client.SuperviseOptions{})
+
+	if address := wait(t, launched); address != "mc.example.com" {
+		t.Errorf("launched %q, want mc.example.com", address)
+	}
+}
@@ -1,5 +1,11 @@
 package client
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // Response represents a Discord RPC response
 type Response struct {
 	Cmd   string `json:"cmd"`
@@ -22,7 +28,6 @@ type Config struct {
 	Environment string `json:"environment"`
 }
 
-// User represents Discord user data
 // PremiumType represents the user's Discord Nitro subscription type
 type PremiumType int
 
@@ -32,12 +37,68 @@ const (
 	PremiumNitro   PremiumType = 2
 )
 
+// User represents Discord user data
 type User struct {
 	ID            string      `json:"id"`
 	Username      string      `json:"username"`
+	GlobalName    string      `json:"global_name,omitempty"`
 	Discriminator string      `json:"discriminator"`
 	Avatar        string      `json:"avatar"`
 	Bot           bool        `json:"bot"`
 	Flags         int         `json:"flags"`
 	PremiumType   PremiumType `json:"premium_type"`
 }
+
+// DefaultCDNHost is used for avatar URLs when the READY data has no cdn_host
+const DefaultCDNHost = "cdn.discordapp.com"
+
+// DisplayName returns the name Discord shows for the user
+func (u *User) DisplayName() string {
+	if u.GlobalName != "" {
+		return u.GlobalName
+	}
+	return u.Username
+}
+
+// AvatarURL returns the URL of the user's avatar on cdnHost, which is
+// Config.CDNHost of the READY data. Animated avatars are GIFs and the others
+// PNGs. Users without an avatar get one of Discord's default avatars. size is
+// a power of two from 16 to 4096, or 0 for the original size
+func (u *User) AvatarURL(cdnHost string, size int) string {
+	if cdnHost == "" {
+		cdnHost = DefaultCDNHost
+	}
+
+	var url string
+	if u.Avatar == "" {
+		url = fmt.Sprintf("https://%s/embed/avatars/%d.png", cdnHost, u.defaultAvatar())
+	} else {
+		ext := "png"
+		if strings.HasPrefix(u.Avatar, "a_") {
+			ext = "gif"
+		}
+		url = fmt.Sprintf("https://%s/avatars/%s/%s.%s", cdnHost, u.ID, u.Avatar, ext)
+	}
+
+	if size > 0 {
+		url += "?size=" + strconv.Itoa(size)
+	}
+	return url
+}
+
+// defaultAvatar returns the index of the default avatar, which depends on the
+// discriminator for legacy usernames and on the user ID otherwise
+func (u *User) defaultAvatar() uint64 {
+	if u.Discriminator != "" && u.Discriminator != "0" {
+		discriminator, _ := strconv.ParseUint(u.Discriminator, 10, 64)
+		return discriminator % 5
+	}
+	id, _ := strconv.ParseUint(u.ID, 10, 64)
+	return (id >> 22) % 6
+}
+
+// AvatarURL returns the URL of the logged in user's avatar on the CDN of the
+// READY data. See User.AvatarURL
+func (h *HandshakeResponse) AvatarURL(size int) string {
+	return h.User.AvatarURL(h.Config.CDNHost, size)
+}
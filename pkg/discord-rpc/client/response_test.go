@@ -0,0 +1,59 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/client"
+)
+
+func TestAvatarURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     client.User
+		cdnHost  string
+		size     int
+		expected string
+	}{
+		{
+			name:     "Static",
+			user:     client.User{ID: "80351110224678912", Avatar: "8342729096ea3675442027381ff50dfe"},
+			cdnHost:  "cdn.discordapp.com",
+			size:     64,
+			expected: "https://cdn.discordapp.com/avatars/80351110224678912/8342729096ea3675442027381ff50dfe.png?size=64",
+		},
+		{
+			name:     "Animated",
+			user:     client.User{ID: "80351110224678912", Avatar: "a_1269e74af4df7417b13759eae50c83dc"},
+			cdnHost:  "media.discordapp.net",
+			expected: "https://media.discordapp.net/avatars/80351110224678912/a_1269e74af4df7417b13759eae50c83dc.gif",
+		},
+		{
+			name:     "Default for a new username",
+			user:     client.User{ID: "80351110224678912", Discriminator: "0"},
+			expected: "https://cdn.discordapp.com/embed/avatars/5.png",
+		},
+		{
+			name:     "Default for a legacy username",
+			user:     client.User{ID: "80351110224678912", Discriminator: "1337"},
+			size:     32,
+			expected: "https://cdn.discordapp.com/embed/avatars/2.png?size=32",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if url := tt.user.AvatarURL(tt.cdnHost, tt.size); url != tt.expected {
+				t.Errorf("AvatarURL() = %s, want %s", url, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	if name := (&client.User{Username: "steve", GlobalName: "Steve"}).DisplayName(); name != "Steve" {
+		t.Errorf("DisplayName() = %s, want Steve", name)
+	}
+	if name := (&client.User{Username: "steve"}).DisplayName(); name != "steve" {
+		t.Errorf("DisplayName() = %s, want steve", name)
+	}
+}
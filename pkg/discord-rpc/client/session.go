@@ -0,0 +1,332 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/ipc"
+)
+
+// ErrConnectionClosed is returned by commands whose connection closed before Discord answered
+var ErrConnectionClosed = errors.New("discord-rpc: connection closed")
+
+// eventBuffer is how many events may wait for the handlers before new ones are dropped
+const eventBuffer = 64
+
+// Error is an error Discord returned in reply to a command
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("discord error %d: %s", e.Code, e.Message)
+}
+
+// Close codes Discord sends in a CLOSE frame
+const (
+	CloseNormal          = 1000
+	CloseInvalidClientID = 4000
+	CloseInvalidOrigin   = 4001
+	CloseRateLimited     = 4002
+	CloseTokenRevoked    = 4003
+	CloseInvalidVersion  = 4004
+	CloseInvalidEncoding = 4005
+)
+
+// CloseError is the reason Discord gave for closing the connection, such as
+// CloseInvalidClientID when Login used an unknown application ID
+type CloseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("discord closed the connection: %s (%d)", e.Message, e.Code)
+}
+
+func decodeClose(data []byte) error {
+	e := &CloseError{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return fmt.Errorf("discord closed the connection: %s", data)
+	}
+	return e
+}
+
+func decodeError(data json.RawMessage) error {
+	e := &Error{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return fmt.Errorf("discord error: %s", data)
+	}
+	return e
+}
+
+// rawFrame is a command response or an event read from the socket
+type rawFrame struct {
+	Cmd   string          `json:"cmd"`
+	Data  json.RawMessage `json:"data"`
+	Event string          `json:"evt"`
+	Nonce string          `json:"nonce"`
+}
+
+// command is a command sent to Discord
+type command struct {
+	Cmd   string `json:"cmd"`
+	Args  any    `json:"args"`
+	Event string `json:"evt,omitempty"`
+	Nonce string `json:"nonce"`
+}
+
+// session is one connection to Discord. A reader goroutine routes responses
+// to the commands waiting for them by nonce and queues everything else as events
+type session struct {
+	conn   *ipc.Conn
+	logger *slog.Logger
+	events chan Event
+	// ready is the READY data of the handshake
+	ready *HandshakeResponse
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]chan rawFrame
+	err     error
+	done    chan struct{}
+}
+
+func newSession(conn *ipc.Conn, logger *slog.Logger) *session {
+	return &session{
+		conn:    conn,
+		logger:  logger,
+		events:  make(chan Event, eventBuffer),
+		pending: map[string]chan rawFrame{},
+		done:    make(chan struct{}),
+	}
+}
+
+// closed reports whether the reader has stopped
+func (s *session) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// handshake sends the handshake and reads the READY event. It must be called
+// before the reader starts
+func (s *session) handshake(ctx context.Context, clientID string) (*rawFrame, error) {
+	payload, err := json.Marshal(Handshake{"1", clientID})
+	if err != nil {
+		return nil, err
+	}
+
+	sock := s.conn.NetConn()
+	stop := context.AfterFunc(ctx, func() {
+		sock.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	var opcode ipc.Opcode
+	var data []byte
+	err = s.conn.WriteFrame(ipc.OpHandshake, payload)
+	if err == nil {
+		opcode, data, err = s.conn.ReadFrame()
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	if !stop() {
+		return nil, ctx.Err()
+	}
+	if opcode == ipc.OpClose {
+		return nil, decodeClose(data)
+	}
+
+	var ready rawFrame
+	if err := json.Unmarshal(data, &ready); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if ready.Event == EventError {
+		return nil, decodeError(ready.Data)
+	}
+	return &ready, nil
+}
+
+// readLoop reads frames until the connection fails or Discord closes it,
+// then fails the pending commands
+func (s *session) readLoop() {
+	var err error
+	for {
+		var opcode ipc.Opcode
+		var payload []byte
+		opcode, payload, err = s.conn.ReadFrame()
+		if err != nil {
+			break
+		}
+
+		switch opcode {
+		case ipc.OpPing:
+			if err := s.send(context.Background(), ipc.OpPong, payload); err != nil {
+				s.logger.Warn("discord-rpc: failed to answer ping", "error", err)
+			}
+			continue
+		case ipc.OpClose:
+			err = decodeClose(payload)
+		case ipc.OpFrame:
+			s.route(payload)
+			continue
+		default:
+			s.logger.Debug("discord-rpc: unexpected frame", "opcode", opcode)
+			continue
+		}
+		break
+	}
+
+	s.conn.Close()
+	s.mu.Lock()
+	s.err = fmt.Errorf("%w: %w", ErrConnectionClosed, err)
+	s.pending = nil
+	s.mu.Unlock()
+	close(s.events)
+	close(s.done)
+}
+
+// route passes a response to the command waiting for it and queues anything else as an event
+func (s *session) route(payload []byte) {
+	var frame rawFrame
+	if err := json.Unmarshal(payload, &frame); err != nil {
+		s.logger.Warn("discord-rpc: invalid frame", "error", err)
+		return
+	}
+
+	if frame.Nonce != "" {
+		s.mu.Lock()
+		reply, ok := s.pending[frame.Nonce]
+		delete(s.pending, frame.Nonce)
+		s.mu.Unlock()
+		if ok {
+			reply <- frame
+			return
+		}
+	}
+
+	if frame.Cmd == "DISPATCH" || frame.Event == EventError {
+		s.dispatch(Event{Name: frame.Event, Data: frame.Data})
+	}
+}
+
+// dispatch queues an event for the handlers, dropping it if they fall too far behind
+func (s *session) dispatch(event Event) {
+	select {
+	case s.events <- event:
+	default:
+		s.logger.Warn("discord-rpc: event dropped", "event", event.Name)
+	}
+}
+
+// call sends a command and waits for its response
+func (s *session) call(ctx context.Context, cmd command) (*rawFrame, error) {
+	cmd.Nonce = getNonce()
+	payload, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := make(chan rawFrame, 1)
+	s.mu.Lock()
+	if s.pending == nil {
+		err := s.err
+		s.mu.Unlock()
+		return nil, err
+	}
+	s.pending[cmd.Nonce] = reply
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, cmd.Nonce)
+		s.mu.Unlock()
+	}()
+
+	if err := s.send(ctx, ipc.OpFrame, payload); err != nil {
+		return nil, err
+	}
+
+	var frame rawFrame
+	select {
+	case frame = <-reply:
+	case <-s.done:
+		// The response may have arrived just before the connection closed
+		select {
+		case frame = <-reply:
+		default:
+			return nil, s.err
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if frame.Event == EventError {
+		return nil, decodeError(frame.Data)
+	}
+	return &frame, nil
+}
+
+// send writes a frame. A write that fails or is cancelled half way leaves the
+// stream corrupt, so it closes the connection
+func (s *session) send(ctx context.Context, opcode ipc.Opcode, payload []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	sock := s.conn.NetConn()
+	stop := context.AfterFunc(ctx, func() {
+		sock.SetWriteDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := s.conn.WriteFrame(opcode, payload); err != nil {
+		s.conn.Close()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	if !stop() {
+		// ctx ended just after the write, so clear the deadline for the next one
+		sock.SetWriteDeadline(time.Time{})
+	}
+	return nil
+}
+
+// closeTimeout bounds how long Close waits to send the CLOSE frame
+const closeTimeout = time.Second
+
+// close sends the CLOSE frame, closes the connection and waits for the reader to stop
+func (s *session) close() error {
+	if !s.closed() {
+		ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		payload, _ := json.Marshal(CloseError{Code: CloseNormal, Message: "closed by client"})
+		if err := s.send(ctx, ipc.OpClose, payload); err != nil {
+			s.logger.Debug("discord-rpc: failed to send close", "error", err)
+		}
+		cancel()
+	}
+
+	err := s.conn.Close()
+	<-s.done
+	if errors.Is(err, net.ErrClosed) {
+		// The reader closed it first
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// State is the connection state reported by Supervise
+type State int
+
+const (
+	// StateConnecting means a connection attempt started
+	StateConnecting State = iota
+	// StateReady means the client is logged in and the presence is restored
+	StateReady
+	// StateDisconnected means an attempt failed or the connection was lost
+	StateDisconnected
+)
+
+func (s State) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateReady:
+		return "ready"
+	case StateDisconnected:
+		return "disconnected"
+	}
+	return "unknown"
+}
+
+// SuperviseOptions configures Supervise
+type SuperviseOptions struct {
+	// MinBackoff is the wait after the first failed attempt or lost
+	// connection. It doubles after every failure up to MaxBackoff, and is
+	// reset once a connection stays up for MaxBackoff. Defaults to one second
+	MinBackoff time.Duration
+	// MaxBackoff is the longest wait between attempts. Defaults to one minute
+	MaxBackoff time.Duration
+	// OnStateChange is called on every state change, with the error that
+	// caused a disconnection. It must not block
+	OnStateChange func(state State, err error)
+}
+
+// Supervise keeps the client logged in until ctx is done. It connects with
+// exponential backoff, so it works whether Discord is running already, is
+// started later or restarts. It also backs off after a lost connection, so a
+// Discord that closes every connection right after READY is not hammered.
+// After every login it subscribes to the events passed to Subscribe and sends
+// the last activity again. When ctx is done it closes the connection and
+// returns ctx.Err()
+func (c *Client) Supervise(ctx context.Context, options SuperviseOptions) error {
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = time.Second
+	}
+	if options.MaxBackoff < options.MinBackoff {
+		options.MaxBackoff = max(time.Minute, options.MinBackoff)
+	}
+	notify := func(state State, err error) {
+		if options.OnStateChange != nil {
+			options.OnStateChange(state, err)
+		}
+	}
+	defer c.Close()
+
+	backoff := options.MinBackoff
+	for {
+		notify(StateConnecting, nil)
+		s, err := c.connect(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err == nil {
+			connected := time.Now()
+			notify(StateReady, nil)
+			select {
+			case <-s.done:
+				err = s.err
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if time.Since(connected) >= options.MaxBackoff {
+				// The connection was stable, so this is not a reconnect loop
+				backoff = options.MinBackoff
+			}
+			c.logger.Warn("discord-rpc: disconnected", "error", err, "retry", backoff)
+		} else {
+			c.logger.Debug("discord-rpc: connection failed", "error", err, "retry", backoff)
+		}
+
+		notify(StateDisconnected, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		backoff = min(backoff*2, options.MaxBackoff)
+	}
+}
+
+// connect logs in and restores the subscriptions and the last activity
+func (c *Client) connect(ctx context.Context) (*session, error) {
+	if _, err := c.Login(ctx); err != nil {
+		return nil, err
+	}
+	s, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	var events []string
+	for event := range c.subscriptions {
+		events = append(events, event)
+	}
+	activity := c.activity
+	c.mu.Unlock()
+
+	for _, event := range events {
+		_, err := s.call(ctx, command{Cmd: "SUBSCRIBE", Args: struct{}{}, Event: event})
+		if err := c.restoreError(err); err != nil {
+			return nil, err
+		}
+	}
+	if activity != nil {
+		_, err := s.call(ctx, setActivityCommand(activity))
+		if err := c.restoreError(err); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// restoreError drops errors Discord returned for a restored command, which
+// another attempt would not fix, and closes the connection on any other error
+func (c *Client) restoreError(err error) error {
+	var discordErr *Error
+	if errors.As(err, &discordErr) {
+		c.logger.Warn("discord-rpc: failed to restore presence", "error", err)
+		return nil
+	}
+	if err != nil {
+		c.Close()
+	}
+	return err
+}
@@ -0,0 +1,247 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/client"
+	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/ipc"
+)
+
+type stateChange struct {
+	state client.State
+	err   error
+}
+
+func TestSupervise(t *testing.T) {
+	// Discord is not running for the first two attempts, then restarts once
+	first, firstDiscord := net.Pipe()
+	second, secondDiscord := net.Pipe()
+	dials := []net.Conn{nil, nil, first, second}
+	c := client.New(
+		client.WithClientID("123"),
+		client.WithSocketPath("fake"),
+		client.WithDialer(func(ctx context.Context, path string) (net.Conn, error) {
+			if len(dials) == 0 {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			conn := dials[0]
+			dials = dials[1:]
+			if conn == nil {
+				return nil, errors.New("connection refused")
+			}
+			return conn, nil
+		}),
+	)
+	defer firstDiscord.Close()
+	defer secondDiscord.Close()
+
+	activity := client.PayloadActivity{Details: "Survival"}
+	if err := c.SetActivityPayload(context.Background(), activity); !errors.Is(err, client.ErrNotLoggedIn) {
+		t.Errorf("SetActivity() error = %v, want ErrNotLoggedIn", err)
+	}
+
+	states := make(chan stateChange, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- c.Supervise(ctx, client.SuperviseOptions{
+			MinBackoff: time.Millisecond,
+			MaxBackoff: 2 * time.Millisecond,
+			OnStateChange: func(state client.State, err error) {
+				states <- stateChange{state, err}
+			},
+		})
+	}()
+
+	expectState := func(expected client.State) stateChange {
+		t.Helper()
+		change := wait(t, states)
+		if change.state != expected {
+			t.Fatalf("state = %v (%v), want %v", change.state, change.err, expected)
+		}
+		return change
+	}
+	for range 2 {
+		expectState(client.StateConnecting)
+		if change := expectState(client.StateDisconnected); change.err == nil {
+			t.Error("failed attempt reported no error")
+		}
+	}
+
+	d := &fakeDiscord{t, firstDiscord}
+	go func() {
+		d.handshake()
+		_, m := d.read()
+		if m.Cmd != "SET_ACTIVITY" {
+			t.Errorf("restored cmd = %s, want SET_ACTIVITY", m.Cmd)
+		}
+		d.reply(m, nil)
+		_, m = d.read()
+		d.reply(m, map[string]any{"evt": m.Event})
+		d.conn.Close()
+	}()
+	expectState(client.StateConnecting)
+	expectState(client.StateReady)
+	if err := c.Subscribe(context.Background(), client.EventActivityJoin); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if change := expectState(client.StateDisconnected); !errors.Is(change.err, client.ErrConnectionClosed) {
+		t.Errorf("disconnect error = %v, want ErrConnectionClosed", change.err)
+	}
+
+	d = &fakeDiscord{t, secondDiscord}
+	restored := make(chan []string, 1)
+	go func() {
+		d.handshake()
+		var commands []string
+		for range 2 {
+			_, m := d.read()
+			var args struct {
+				Activity *client.PayloadActivity `json:"activity"`
+			}
+			json.Unmarshal(m.Args, &args)
+			switch {
+			case m.Cmd == "SUBSCRIBE":
+				commands = append(commands, m.Cmd+" "+m.Event)
+			case args.Activity != nil:
+				commands = append(commands, m.Cmd+" "+args.Activity.Details)
+			}
+			d.reply(m, nil)
+		}
+		restored <- commands
+	}()
+	expectState(client.StateConnecting)
+	expectState(client.StateReady)
+	commands := wait(t, restored)
+	sort.Strings(commands)
+	if len(commands) != 2 || commands[0] != "SET_ACTIVITY Survival" || commands[1] != "SUBSCRIBE ACTIVITY_JOIN" {
+		t.Errorf("restored commands = %v", commands)
+	}
+
+	closed := make(chan ipc.Opcode, 1)
+	go func() {
+		opcode, _ := d.read()
+		closed <- opcode
+	}()
+	cancel()
+	if opcode := wait(t, closed); opcode != ipc.OpClose {
+		t.Errorf("opcode on cancel = %v, want CLOSE", opcode)
+	}
+	if err := wait(t, result); !errors.Is(err, context.Canceled) {
+		t.Errorf("Supervise() = %v, want context.Canceled", err)
+	}
+	if err := c.SetActivityPayload(context.Background(), activity); !errors.Is(err, client.ErrNotLoggedIn) {
+		t.Errorf("SetActivity() after Supervise error = %v, want ErrNotLoggedIn", err)
+	}
+}
+
+func TestSubscribeBeforeConnect(t *testing.T) {
+	c, d := newFakeDiscord(t)
+
+	// Discord is not running yet, but the subscription is kept for later
+	if err := c.Subscribe(context.Background(), client.EventActivityJoin); !errors.Is(err, client.ErrNotLoggedIn) {
+		t.Errorf("Subscribe() error = %v, want ErrNotLoggedIn", err)
+	}
+
+	restored := make(chan message, 1)
+	go func() {
+		d.handshake()
+		_, m := d.read()
+		d.reply(m, map[string]any{"evt": m.Event})
+		restored <- m
+	}()
+	ready := make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Supervise(ctx, client.SuperviseOptions{
+		OnStateChange: func(state client.State, err error) {
+			if state == client.StateReady {
+				ready <- struct{}{}
+			}
+		},
+	})
+	if m := wait(t, restored); m.Cmd != "SUBSCRIBE" || m.Event != client.EventActivityJoin {
+		t.Errorf("restored command = %s %s, want SUBSCRIBE ACTIVITY_JOIN", m.Cmd, m.Event)
+	}
+	wait(t, ready)
+
+	// A subscription Discord rejects fails with its error and is forgotten
+	go func() {
+		_, m := d.read()
+		d.send(ipc.OpFrame, map[string]any{"cmd": m.Cmd, "evt": client.EventError, "nonce": m.Nonce, "data": map[string]any{"code": 4006, "message": "Invalid event"}})
+	}()
+	var discordErr *client.Error
+	if err := c.Subscribe(context.Background(), "BOGUS"); !errors.As(err, &discordErr) {
+		t.Errorf("Subscribe() error = %v, want a Discord error", err)
+	}
+
+	go func() {
+		_, m := d.read()
+		d.reply(m, nil)
+	}()
+	if err := c.Unsubscribe(context.Background(), client.EventActivityJoin); err != nil {
+		t.Errorf("Unsubscribe() error = %v", err)
+	}
+}
+
+func TestSuperviseBacksOffAfterDisconnect(t *testing.T) {
+	// Discord accepts every handshake and closes the connection right away
+	dials := make(chan time.Time, 16)
+	c := client.New(
+		client.WithClientID("123"),
+		client.WithSocketPath("fake"),
+		client.WithDialer(func(ctx context.Context, path string) (net.Conn, error) {
+			clientSide, discordSide := net.Pipe()
+			dials <- time.Now()
+			go func() {
+				d := &fakeDiscord{t, discordSide}
+				d.handshake()
+				d.send(ipc.OpClose, map[string]any{"code": client.CloseRateLimited, "message": "rate limited"})
+				discordSide.Close()
+			}()
+			return clientSide, nil
+		}),
+	)
+
+	const minBackoff = 20 * time.Millisecond
+	disconnects := make(chan error, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- c.Supervise(ctx, client.SuperviseOptions{
+			MinBackoff: minBackoff,
+			MaxBackoff: time.Second,
+			OnStateChange: func(state client.State, err error) {
+				if state == client.StateDisconnected {
+					disconnects <- err
+				}
+			},
+		})
+	}()
+
+	previous := wait(t, dials)
+	for i := range 3 {
+		var closeErr *client.CloseError
+		if err := wait(t, disconnects); !errors.As(err, &closeErr) || closeErr.Code != client.CloseRateLimited {
+			t.Errorf("disconnect error = %v, want CloseRateLimited", err)
+		}
+		dial := wait(t, dials)
+		if want := minBackoff << i; dial.Sub(previous) < want {
+			t.Errorf("reconnect %d after %v, want at least %v", i+1, dial.Sub(previous), want)
+		}
+		previous = dial
+	}
+	wait(t, disconnects)
+
+	cancel()
+	if err := wait(t, result); !errors.Is(err, context.Canceled) {
+		t.Errorf("Supervise() = %v, want context.Canceled", err)
+	}
+}
@@ -31,27 +31,47 @@ var ActivityType = struct {
 	Competing: 5,
 }
 
+// StatusDisplayType namespace for which field the member list shows after "Playing"
+var StatusDisplayType = struct {
+	Name    int
+	State   int
+	Details int
+}{
+	Name:    0,
+	State:   1,
+	Details: 2,
+}
+
 type PayloadActivity struct {
-	Type       int                `json:"type,omitempty"`
-	Details    string             `json:"details,omitempty"`
-	State      string             `json:"state,omitempty"`
-	Assets     PayloadAssets      `json:"assets,omitempty"`
-	Party      *PayloadParty      `json:"party,omitempty"`
-	Timestamps *PayloadTimestamps `json:"timestamps,omitempty"`
-	Secrets    *PayloadSecrets    `json:"secrets,omitempty"`
-	Buttons    []*PayloadButton   `json:"buttons,omitempty"`
+	// Name overrides the application name shown as "Playing <name>"
+	Name              string             `json:"name,omitempty"`
+	Type              int                `json:"type,omitempty"`
+	StatusDisplayType int                `json:"status_display_type,omitempty"`
+	Details           string             `json:"details,omitempty"`
+	DetailsURL        string             `json:"details_url,omitempty"`
+	State             string             `json:"state,omitempty"`
+	StateURL          string             `json:"state_url,omitempty"`
+	Assets            PayloadAssets      `json:"assets,omitempty"`
+	Party             *PayloadParty      `json:"party,omitempty"`
+	Timestamps        *PayloadTimestamps `json:"timestamps,omitempty"`
+	Secrets           *PayloadSecrets    `json:"secrets,omitempty"`
+	Instance          bool               `json:"instance,omitempty"`
+	Buttons           []*PayloadButton   `json:"buttons,omitempty"`
 }
 
 type PayloadAssets struct {
 	LargeImage string `json:"large_image,omitempty"`
 	LargeText  string `json:"large_text,omitempty"`
+	LargeURL   string `json:"large_url,omitempty"`
 	SmallImage string `json:"small_image,omitempty"`
 	SmallText  string `json:"small_text,omitempty"`
+	SmallURL   string `json:"small_url,omitempty"`
 }
 
 type PayloadParty struct {
-	ID   string `json:"id,omitempty"`
-	Size [2]int `json:"size,omitempty"`
+	ID string `json:"id,omitempty"`
+	// Size is the current and maximum size. A party without a size leaves it nil
+	Size *[2]int `json:"size,omitempty"`
 }
 
 type PayloadTimestamps struct {
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"unicode/utf8"
+)
+
+// Limits Discord enforces on an activity
+const (
+	// MinTextLength and MaxTextLength bound the name, details, state and image texts
+	MinTextLength = 2
+	MaxTextLength = 128
+	// MaxButtons is the number of buttons an activity can have
+	MaxButtons = 2
+	// MaxButtonLabelLength bounds the label of a button
+	MaxButtonLabelLength = 32
+	// MaxURLLength bounds the URLs of the buttons, texts and images
+	MaxURLLength = 512
+	// MaxImageKeyLength bounds the asset key or URL of an image
+	MaxImageKeyLength = 256
+	// MaxIDLength bounds the party ID and the secrets
+	MaxIDLength = 128
+)
+
+// minMillis is the first millisecond timestamp of 2001. Smaller timestamps are
+// almost certainly seconds, which Discord would show as January 1970
+const minMillis = 1_000_000_000_000
+
+// ValidationError describes a field of an activity that Discord would reject
+type ValidationError struct {
+	// Field is the JSON path of the field, such as "buttons[1].url"
+	Field string
+	// Message says what is wrong with it
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid activity %s: %s", e.Field, e.Message)
+}
+
+// Validate checks the activity against Discord's limits. See PayloadActivity.Validate
+func (activity *Activity) Validate() error {
+	return mapActivity(activity).Validate()
+}
+
+// Validate checks the activity against Discord's limits and returns every
+// problem as a *ValidationError, joined with errors.Join, or nil
+func (activity *PayloadActivity) Validate() error {
+	var errs []error
+	invalid := func(field, format string, args ...any) {
+		errs = append(errs, &ValidationError{field, fmt.Sprintf(format, args...)})
+	}
+	text := func(field, value string) {
+		if n := utf8.RuneCountInString(value); value != "" && (n < MinTextLength || n > MaxTextLength) {
+			invalid(field, "has %d characters, want %d to %d", n, MinTextLength, MaxTextLength)
+		}
+	}
+	maxLength := func(field, value string, limit int) {
+		if n := utf8.RuneCountInString(value); n > limit {
+			invalid(field, "has %d characters, want at most %d", n, limit)
+		}
+	}
+	link := func(field, value string) {
+		maxLength(field, value, MaxURLLength)
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid(field, "%q is not an http or https URL", value)
+		}
+	}
+	optionalLink := func(field, value string) {
+		if value != "" {
+			link(field, value)
+		}
+	}
+
+	text("name", activity.Name)
+	text("details", activity.Details)
+	text("state", activity.State)
+	text("assets.large_text", activity.Assets.LargeText)
+	text("assets.small_text", activity.Assets.SmallText)
+	maxLength("assets.large_image", activity.Assets.LargeImage, MaxImageKeyLength)
+	maxLength("assets.small_image", activity.Assets.SmallImage, MaxImageKeyLength)
+	optionalLink("details_url", activity.DetailsURL)
+	optionalLink("state_url", activity.StateURL)
+	optionalLink("assets.large_url", activity.Assets.LargeURL)
+	optionalLink("assets.small_url", activity.Assets.SmallURL)
+
+	if t := activity.StatusDisplayType; t < StatusDisplayType.Name || t > StatusDisplayType.Details {
+		invalid("status_display_type", "is %d, want %d to %d", t, StatusDisplayType.Name, StatusDisplayType.Details)
+	}
+
+	if len(activity.Buttons) > MaxButtons {
+		invalid("buttons", "has %d buttons, want at most %d", len(activity.Buttons), MaxButtons)
+	}
+	for i, button := range activity.Buttons {
+		field := fmt.Sprintf("buttons[%d]", i)
+		if button == nil {
+			invalid(field, "is nil")
+			continue
+		}
+		if n := utf8.RuneCountInString(button.Label); n < 1 || n > MaxButtonLabelLength {
+			invalid(field+".label", "has %d characters, want 1 to %d", n, MaxButtonLabelLength)
+		}
+		link(field+".url", button.Url)
+	}
+
+	if party := activity.Party; party != nil {
+		maxLength("party.id", party.ID, MaxIDLength)
+		// Discord accepts a party without a size
+		if party.Size != nil {
+			if current, size := party.Size[0], party.Size[1]; current < 1 || size < current {
+				invalid("party.size", "is [%d, %d], want 1 <= current size <= maximum size", current, size)
+			}
+		}
+	}
+
+	if secrets := activity.Secrets; secrets != nil {
+		maxLength("secrets.match", secrets.Match, MaxIDLength)
+		maxLength("secrets.join", secrets.Join, MaxIDLength)
+		maxLength("secrets.spectate", secrets.Spectate, MaxIDLength)
+	}
+
+	if timestamps := activity.Timestamps; timestamps != nil {
+		if start := timestamps.Start; start != nil && *start < minMillis {
+			invalid("timestamps.start", "%d looks like seconds, want milliseconds", *start)
+		}
+		if end := timestamps.End; end != nil && *end < minMillis {
+			invalid("timestamps.end", "%d looks like seconds, want milliseconds", *end)
+		}
+		if start, end := timestamps.Start, timestamps.End; start != nil && end != nil && *end < *start {
+			invalid("timestamps.end", "is before the start")
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,178 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/client"
+)
+
+func TestValidate(t *testing.T) {
+	start := time.UnixMilli(1_700_000_000_000)
+	before := start.Add(-time.Hour)
+	seconds := uint64(1_700_000_000)
+
+	tests := []struct {
+		name     string
+		activity client.Activity
+		payload  *client.PayloadActivity
+		expected []string
+	}{
+		{
+			name: "Valid",
+			activity: client.Activity{
+				Details:    "Playing Minecraft",
+				State:      "In a party",
+				LargeImage: "https://cdn.modrinth.com/icon.png",
+				LargeText:  "All the Mods 9",
+				Party:      &client.Party{ID: "party", Players: 2, MaxPlayers: 4},
+				Timestamps: &client.Timestamps{Start: &start},
+				Buttons:    []*client.Button{{Label: "Modpack", Url: "https://modrinth.com/modpack/atm9"}},
+			},
+		},
+		{
+			name:     "Text lengths",
+			activity: client.Activity{Details: "x", State: strings.Repeat("é", 129), SmallText: "ok"},
+			expected: []string{"details", "state"},
+		},
+		{
+			name: "Buttons",
+			activity: client.Activity{Buttons: []*client.Button{
+				{Label: "Wiki", Url: "javascript:alert(1)"},
+				{Label: strings.Repeat("b", 33), Url: "https://example.com"},
+				{Label: "Third", Url: "https://example.com"},
+			}},
+			expected: []string{"buttons", "buttons[0].url", "buttons[1].label"},
+		},
+		{
+			name: "Links",
+			activity: client.Activity{
+				Name:              "All the Mods 9",
+				StatusDisplayType: client.StatusDisplayType.Details,
+				Details:           "Playing Minecraft",
+				DetailsURL:        "https://modrinth.com/modpack/atm9",
+				StateURL:          "modrinth.com",
+				LargeURL:          "ftp://example.com/icon.png",
+				SmallURL:          "https://example.com/" + strings.Repeat("a", 512),
+			},
+			expected: []string{"state_url", "assets.large_url", "assets.small_url"},
+		},
+		{
+			name:     "Status display type",
+			activity: client.Activity{Name: "x", StatusDisplayType: 3},
+			expected: []string{"name", "status_display_type"},
+		},
+		{
+			name:     "Party without a size",
+			activity: client.Activity{Party: &client.Party{ID: "party"}},
+		},
+		{
+			name:     "Party size",
+			activity: client.Activity{Party: &client.Party{Players: 5, MaxPlayers: 4}},
+			expected: []string{"party.size"},
+		},
+		{
+			name:     "Empty party size",
+			payload:  &client.PayloadActivity{Party: &client.PayloadParty{Size: &[2]int{}}},
+			expected: []string{"party.size"},
+		},
+		{
+			name:     "End before start",
+			activity: client.Activity{Timestamps: &client.Timestamps{Start: &start, End: &before}},
+			expected: []string{"timestamps.end"},
+		},
+		{
+			name:     "Seconds instead of milliseconds",
+			payload:  &client.PayloadActivity{Timestamps: &client.PayloadTimestamps{Start: &seconds}},
+			expected: []string{"timestamps.start"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.payload != nil {
+				err = tt.payload.Validate()
+			} else {
+				err = tt.activity.Validate()
+			}
+
+			var fields []string
+			for _, e := range unwrapAll(err) {
+				var validationErr *client.ValidationError
+				if !errors.As(e, &validationErr) {
+					t.Fatalf("Validate() error %v is not a ValidationError", e)
+				}
+				fields = append(fields, validationErr.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.expected) {
+				t.Errorf("Validate() fields = %v, want %v (%v)", fields, tt.expected, err)
+			}
+		})
+	}
+}
+
+func unwrapAll(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+func TestSetActivityValidates(t *testing.T) {
+	c, d := newFakeDiscord(t)
+
+	// Validation happens before anything is sent, even without a connection
+	err := c.SetActivity(context.Background(), client.Activity{Details: "x"})
+	var validationErr *client.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("SetActivity() error = %v, want a ValidationError", err)
+	}
+
+	d.login(c)
+	start := time.UnixMilli(1_700_000_000_000)
+	sent := make(chan *client.PayloadActivity, 1)
+	go func() {
+		_, m := d.read()
+		var args struct {
+			Activity *client.PayloadActivity `json:"activity"`
+		}
+		decode(t, m.Args, &args)
+		sent <- args.Activity
+		d.reply(m, nil)
+	}()
+	if err := c.SetActivity(context.Background(), client.Activity{Details: "Playing Minecraft", Timestamps: &client.Timestamps{Start: &start}}); err != nil {
+		t.Fatalf("SetActivity() error = %v", err)
+	}
+	if activity := wait(t, sent); activity.Details != "Playing Minecraft" || *activity.Timestamps.Start != 1_700_000_000_000 {
+		t.Errorf("sent activity = %+v", activity)
+	}
+}
+
+func TestClearActivity(t *testing.T) {
+	c, d := newFakeDiscord(t)
+	d.login(c)
+
+	sent := make(chan string, 1)
+	go func() {
+		_, m := d.read()
+		var args map[string]json.RawMessage
+		decode(t, m.Args, &args)
+		sent <- m.Cmd + " " + string(args["activity"])
+		d.reply(m, nil)
+	}()
+	if err := c.ClearActivity(context.Background()); err != nil {
+		t.Fatalf("ClearActivity() error = %v", err)
+	}
+	if cmd := wait(t, sent); cmd != "SET_ACTIVITY null" {
+		t.Errorf("sent %q, want SET_ACTIVITY null", cmd)
+	}
+}
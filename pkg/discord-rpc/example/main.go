@@ -14,13 +14,11 @@ func main() {
 	}
 
 	now := time.Now()
-	timestamp := uint64(now.Unix())
-	err = client.SetActivity(client.PayloadActivity{
-		Type:    client.ActivityType.Playing,
+	err = client.SetActivity(client.Activity{
 		Details: "Playing Minecraft",
 		State:   "In Game",
-		Timestamps: &client.PayloadTimestamps{
-			Start: &timestamp,
+		Timestamps: &client.Timestamps{
+			Start: &now,
 		},
 	})
 
@@ -0,0 +1,78 @@
+//go:build !windows
+
+package ipc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/ipc"
+)
+
+func listen(t *testing.T, path string) *net.UnixListener {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func TestCandidates(t *testing.T) {
+	root := t.TempDir()
+	runtime := filepath.Join(root, "run")
+	tmp := filepath.Join(root, "tmp")
+	t.Setenv("XDG_RUNTIME_DIR", runtime)
+	t.Setenv("TMPDIR", tmp)
+	t.Setenv("TMP", "")
+	t.Setenv("TEMP", tmp+"/")
+
+	// A stale socket whose Discord exited, which Candidates cannot tell apart
+	stale := listen(t, filepath.Join(runtime, "discord-ipc-1"))
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+	listen(t, filepath.Join(runtime, "app/com.discordapp.Discord/discord-ipc-0"))
+	listen(t, filepath.Join(tmp, ".flatpak/dev.vencord.Vesktop/xdg-run/discord-ipc-3"))
+	// Not a socket
+	os.WriteFile(filepath.Join(runtime, "discord-ipc-0"), nil, 0o644)
+
+	var got []string
+	for _, path := range ipc.Candidates() {
+		if strings.HasPrefix(path, root) {
+			got = append(got, strings.TrimPrefix(path, root))
+		}
+	}
+	expected := []string{
+		"/run/discord-ipc-1",
+		"/run/app/com.discordapp.Discord/discord-ipc-0",
+		"/tmp/.flatpak/dev.vencord.Vesktop/xdg-run/discord-ipc-3",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Candidates() = %v, want %v", got, expected)
+	}
+
+	conn, path, err := ipc.DialFirst(context.Background(), []string{root + expected[0], root + expected[1]}, ipc.Dial)
+	if err != nil {
+		t.Fatalf("DialFirst() error = %v", err)
+	}
+	conn.Close()
+	if path != root+expected[1] {
+		t.Errorf("DialFirst() path = %s, want %s", path, root+expected[1])
+	}
+
+	if _, _, err := ipc.DialFirst(context.Background(), []string{root + expected[0]}, ipc.Dial); !errors.Is(err, ipc.ErrNotFound) {
+		t.Errorf("DialFirst() error = %v, want ErrNotFound", err)
+	}
+	if _, _, err := ipc.DialFirst(context.Background(), nil, ipc.Dial); !errors.Is(err, ipc.ErrNotFound) {
+		t.Errorf("DialFirst() error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,96 @@
+package ipc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Opcode is the type of a frame
+type Opcode uint32
+
+const (
+	// OpHandshake opens the connection with the client ID
+	OpHandshake Opcode = 0
+	// OpFrame carries a command, a response or an event
+	OpFrame Opcode = 1
+	// OpClose ends the connection, with a code and a message when Discord sends it
+	OpClose Opcode = 2
+	// OpPing asks the other end to answer with OpPong and the same payload
+	OpPing Opcode = 3
+	// OpPong answers OpPing
+	OpPong Opcode = 4
+)
+
+func (o Opcode) String() string {
+	switch o {
+	case OpHandshake:
+		return "HANDSHAKE"
+	case OpFrame:
+		return "FRAME"
+	case OpClose:
+		return "CLOSE"
+	case OpPing:
+		return "PING"
+	case OpPong:
+		return "PONG"
+	}
+	return fmt.Sprintf("Opcode(%d)", uint32(o))
+}
+
+// MaxPayloadSize is the largest frame payload ReadFrame accepts. Discord's
+// payloads are a few kilobytes, so a larger length means the stream is corrupt
+const MaxPayloadSize = 1 << 20
+
+// frameHeaderSize is the size of the opcode and the payload length, both
+// little-endian 32-bit integers
+const frameHeaderSize = 8
+
+// FrameTooLargeError is returned when a frame declares a payload larger than MaxPayloadSize
+type FrameTooLargeError struct {
+	Length uint32
+}
+
+func (e *FrameTooLargeError) Error() string {
+	return fmt.Sprintf("frame payload of %d bytes exceeds the maximum of %d", e.Length, MaxPayloadSize)
+}
+
+// ReadFrame reads one frame from r and returns its opcode and payload. It
+// keeps reading until the whole frame arrived, and reads nothing past it, so
+// frames written together are read one at a time
+func ReadFrame(r io.Reader) (Opcode, []byte, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	opcode := binary.LittleEndian.Uint32(header[0:4])
+	length := binary.LittleEndian.Uint32(header[4:8])
+	if length > MaxPayloadSize {
+		return 0, nil, &FrameTooLargeError{length}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	return Opcode(opcode), payload, nil
+}
+
+// WriteFrame writes the opcode and payload to w as one frame in a single Write
+func WriteFrame(w io.Writer, opcode Opcode, payload []byte) error {
+	if len(payload) > MaxPayloadSize {
+		return &FrameTooLargeError{uint32(len(payload))}
+	}
+
+	buf := make([]byte, frameHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(opcode))
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(len(payload)))
+	copy(buf[frameHeaderSize:], payload)
+
+	_, err := w.Write(buf)
+	return err
+}
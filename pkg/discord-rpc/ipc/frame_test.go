@@ -0,0 +1,121 @@
+package ipc_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/discord-rpc/ipc"
+)
+
+func header(opcode, length uint32) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint32(buf[0:4], opcode)
+	binary.LittleEndian.PutUint32(buf[4:8], length)
+	return buf
+}
+
+func frame(opcode uint32, payload string) []byte {
+	return append(header(opcode, uint32(len(payload))), payload...)
+}
+
+// pipe returns a connection whose peer writes the chunks one Write at a time and then closes
+func pipe(t *testing.T, chunks ...[]byte) *ipc.Conn {
+	client, server := net.Pipe()
+	t.Cleanup(func() { client.Close() })
+	go func() {
+		defer server.Close()
+		for _, chunk := range chunks {
+			if _, err := server.Write(chunk); err != nil {
+				return
+			}
+		}
+	}()
+	return ipc.NewConn(client)
+}
+
+type result struct {
+	opcode  ipc.Opcode
+	payload string
+}
+
+func TestReadFrame(t *testing.T) {
+	large := strings.Repeat("x", 64<<10)
+	twoFrames := append(frame(1, `{"evt":"READY"}`), frame(3, "ping")...)
+	split := frame(1, `{"cmd":"SET_ACTIVITY"}`)
+
+	tests := []struct {
+		name     string
+		chunks   [][]byte
+		expected []result
+		wantErr  error
+	}{
+		{name: "Single frame", chunks: [][]byte{frame(1, `{"cmd":"DISPATCH"}`)}, expected: []result{{1, `{"cmd":"DISPATCH"}`}}},
+		{name: "Empty payload", chunks: [][]byte{frame(2, "")}, expected: []result{{2, ""}}},
+		{name: "Payload larger than one read", chunks: [][]byte{frame(1, large)}, expected: []result{{1, large}}},
+		{name: "Frames in one write", chunks: [][]byte{twoFrames}, expected: []result{{1, `{"evt":"READY"}`}, {3, "ping"}}},
+		{name: "Partial writes", chunks: [][]byte{split[:3], split[3:10], split[10:]}, expected: []result{{1, `{"cmd":"SET_ACTIVITY"}`}}},
+		{name: "Truncated header", chunks: [][]byte{header(1, 4)[:5]}, wantErr: io.ErrUnexpectedEOF},
+		{name: "Truncated payload", chunks: [][]byte{header(1, 10), []byte("abc")}, wantErr: io.ErrUnexpectedEOF},
+		{name: "Closed", wantErr: io.EOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := pipe(t, tt.chunks...)
+			for _, expected := range tt.expected {
+				opcode, payload, err := conn.ReadFrame()
+				if err != nil {
+					t.Fatalf("ReadFrame() error = %v", err)
+				}
+				if opcode != expected.opcode || string(payload) != expected.payload {
+					t.Errorf("ReadFrame() = %d, %.40q, want %d, %.40q", opcode, payload, expected.opcode, expected.payload)
+				}
+			}
+			if tt.wantErr != nil {
+				if _, _, err := conn.ReadFrame(); !errors.Is(err, tt.wantErr) {
+					t.Errorf("ReadFrame() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestReadFrameTooLarge(t *testing.T) {
+	conn := pipe(t, header(1, ipc.MaxPayloadSize+1))
+	_, _, err := conn.ReadFrame()
+	var tooLarge *ipc.FrameTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Length != ipc.MaxPayloadSize+1 {
+		t.Errorf("ReadFrame() error = %v, want FrameTooLargeError", err)
+	}
+}
+
+func TestWriteFrame(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		conn := ipc.NewConn(client)
+		conn.WriteFrame(0, []byte(`{"v":1,"client_id":"123"}`))
+		conn.WriteFrame(1, nil)
+	}()
+
+	opcode, payload, err := ipc.ReadFrame(server)
+	if err != nil || opcode != 0 || string(payload) != `{"v":1,"client_id":"123"}` {
+		t.Errorf("ReadFrame() = %d, %q, %v", opcode, payload, err)
+	}
+	opcode, payload, err = ipc.ReadFrame(server)
+	if err != nil || opcode != 1 || len(payload) != 0 {
+		t.Errorf("ReadFrame() = %d, %q, %v", opcode, payload, err)
+	}
+
+	var buf bytes.Buffer
+	if err := ipc.WriteFrame(&buf, 1, make([]byte, ipc.MaxPayloadSize+1)); err == nil || buf.Len() != 0 {
+		t.Errorf("WriteFrame() of an oversized payload = %v, wrote %d bytes", err, buf.Len())
+	}
+}
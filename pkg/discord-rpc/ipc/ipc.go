@@ -1,81 +1,128 @@
 package ipc
 
 import (
-	"bytes"
-	"encoding/binary"
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"net"
-	"os"
+	"path/filepath"
 )
 
-var socket net.Conn
+// socket is the connection used by the package-level functions.
+var socket *Conn
+
+// ErrNotFound is returned when no Discord IPC socket accepts a connection
+var ErrNotFound = errors.New("no Discord IPC socket found")
 
 // Choose the right directory to the ipc socket and return it
+//
+// Deprecated: Discord may listen in one of several directories; use Candidates
 func GetIpcPath() string {
-	variablesnames := []string{"XDG_RUNTIME_DIR", "TMPDIR", "TMP", "TEMP"}
+	return filepath.Dir(DefaultPath())
+}
 
-	if _, err := os.Stat("/run/user/1000/snap.discord"); err == nil {
-		return "/run/user/1000/snap.discord"
+// DialFirst tries the paths in order and returns the first connection that
+// opens together with its path
+func DialFirst(ctx context.Context, paths []string, dial func(ctx context.Context, path string) (net.Conn, error)) (net.Conn, string, error) {
+	var lastErr error
+	for _, path := range paths {
+		conn, err := dial(ctx, path)
+		if err == nil {
+			return conn, path, nil
+		}
+		if ctx.Err() != nil {
+			return nil, "", ctx.Err()
+		}
+		lastErr = err
 	}
-
-	if _, err := os.Stat("/run/user/1000/.flatpak/com.discordapp.Discord/xdg-run"); err == nil {
-		return "/run/user/1000/.flatpak/com.discordapp.Discord/xdg-run"
+	if lastErr != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrNotFound, lastErr)
 	}
+	return nil, "", ErrNotFound
+}
 
-	for _, variablename := range variablesnames {
-		path, exists := os.LookupEnv(variablename)
+// Conn is a connection to the Discord IPC socket. It is not safe for
+// concurrent use; callers serialize access.
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
 
-		if exists {
-			return path
-		}
-	}
+// NewConn wraps an open socket.
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{conn: conn, reader: bufio.NewReader(conn)}
+}
 
-	return "/tmp"
+// NetConn returns the underlying socket.
+func (c *Conn) NetConn() net.Conn {
+	return c.conn
 }
 
-func CloseSocket() error {
-	if socket != nil {
-		socket.Close()
-		socket = nil
-	}
-	return nil
+// Close closes the socket.
+func (c *Conn) Close() error {
+	return c.conn.Close()
 }
 
-// Read the socket response
-func Read() (string, error) {
-	buf := make([]byte, 512)
-	payloadlength, err := socket.Read(buf)
+// ReadFrame reads the next frame and returns its opcode and payload.
+func (c *Conn) ReadFrame() (Opcode, []byte, error) {
+	opcode, payload, err := ReadFrame(c.reader)
 	if err != nil {
-		return "", fmt.Errorf("failed to read from socket: %w", err)
+		return 0, nil, fmt.Errorf("failed to read from socket: %w", err)
 	}
+	return opcode, payload, nil
+}
 
-	buffer := new(bytes.Buffer)
-	for i := 8; i < payloadlength; i++ {
-		buffer.WriteByte(buf[i])
+// WriteFrame writes a frame to the socket.
+func (c *Conn) WriteFrame(opcode Opcode, payload []byte) error {
+	if err := WriteFrame(c.conn, opcode, payload); err != nil {
+		return fmt.Errorf("failed to write to socket: %w", err)
 	}
-
-	return buffer.String(), nil
+	return nil
 }
 
-// Send opcode and payload to the unix socket
-func Send(opcode int, payload string) (string, error) {
-	buf := new(bytes.Buffer)
-
-	err := binary.Write(buf, binary.LittleEndian, int32(opcode))
+// Read the payload of the next frame
+func (c *Conn) Read() (string, error) {
+	_, payload, err := c.ReadFrame()
 	if err != nil {
-		return "", fmt.Errorf("failed to write opcode: %w", err)
+		return "", err
 	}
+	return string(payload), nil
+}
 
-	err = binary.Write(buf, binary.LittleEndian, int32(len(payload)))
-	if err != nil {
-		return "", fmt.Errorf("failed to write payload length: %w", err)
+// Send opcode and payload to the socket and read the response
+func (c *Conn) Send(opcode Opcode, payload string) (string, error) {
+	if err := c.WriteFrame(opcode, []byte(payload)); err != nil {
+		return "", err
 	}
+	return c.Read()
+}
 
-	buf.Write([]byte(payload))
-	_, err = socket.Write(buf.Bytes())
+// OpenSocket opens the first Discord IPC socket that accepts a connection
+func OpenSocket() error {
+	sock, _, err := DialFirst(context.Background(), Candidates(), Dial)
 	if err != nil {
-		return "", fmt.Errorf("failed to write to socket: %w", err)
+		return err
+	}
+
+	socket = NewConn(sock)
+	return nil
+}
+
+func CloseSocket() error {
+	if socket != nil {
+		socket.Close()
+		socket = nil
 	}
+	return nil
+}
+
+// Read the socket response
+func Read() (string, error) {
+	return socket.Read()
+}
 
-	return Read()
+// Send opcode and payload to the socket
+func Send(opcode Opcode, payload string) (string, error) {
+	return socket.Send(opcode, payload)
 }
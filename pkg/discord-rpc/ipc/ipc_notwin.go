@@ -1,19 +1,88 @@
+//go:build !windows
 // +build !windows
 
 package ipc
 
 import (
+	"context"
+	"fmt"
 	"net"
+	"os"
+	"path/filepath"
 	"time"
 )
 
-// OpenSocket opens the discord-ipc-0 unix socket
-func OpenSocket() error {
-	sock, err := net.DialTimeout("unix", GetIpcPath()+"/discord-ipc-0", time.Second*2)
-	if err != nil {
-		return err
+// subdirectories of a runtime or temporary directory where sandboxed Discord
+// clients put their sockets, after the directory itself
+var socketSubdirs = []string{
+	"",
+	"snap.discord",
+	"snap.discord-canary",
+	"snap.discord-ptb",
+	"app/com.discordapp.Discord",
+	"app/com.discordapp.DiscordCanary",
+	"app/com.discordapp.DiscordPTB",
+	"app/dev.vencord.Vesktop",
+	".flatpak/com.discordapp.Discord/xdg-run",
+	".flatpak/com.discordapp.DiscordCanary/xdg-run",
+	".flatpak/com.discordapp.DiscordPTB/xdg-run",
+	".flatpak/dev.vencord.Vesktop/xdg-run",
+}
+
+// searchDirs returns the directories that may hold the socket, without duplicates
+func searchDirs() []string {
+	var dirs []string
+	seen := map[string]bool{}
+	add := func(dir string) {
+		if dir == "" {
+			return
+		}
+		dir = filepath.Clean(dir)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	add(os.Getenv("XDG_RUNTIME_DIR"))
+	add(fmt.Sprintf("/run/user/%d", os.Getuid()))
+	for _, variablename := range []string{"TMPDIR", "TMP", "TEMP"} {
+		add(os.Getenv(variablename))
 	}
+	add("/tmp")
+	return dirs
+}
+
+// Candidates returns the Discord IPC sockets that exist, in the order they
+// should be tried: discord-ipc-0 to discord-ipc-9 in the runtime directory,
+// the temporary directories and /tmp, each also inside the snap and flatpak
+// subdirectories of Discord, its Canary and PTB builds and Vesktop
+func Candidates() []string {
+	var paths []string
+	for _, dir := range searchDirs() {
+		for _, subdir := range socketSubdirs {
+			for i := 0; i < 10; i++ {
+				path := filepath.Join(dir, subdir, fmt.Sprintf("discord-ipc-%d", i))
+				if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+					paths = append(paths, path)
+				}
+			}
+		}
+	}
+	return paths
+}
+
+// DefaultPath returns the first socket Candidates finds, or discord-ipc-0 in
+// the runtime directory if there is none
+func DefaultPath() string {
+	if paths := Candidates(); len(paths) > 0 {
+		return paths[0]
+	}
+	return filepath.Join(searchDirs()[0], "discord-ipc-0")
+}
 
-	socket = sock
-	return nil
+// Dial connects to the unix socket at path, giving up after two seconds
+func Dial(ctx context.Context, path string) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: time.Second * 2}
+	return dialer.DialContext(ctx, "unix", path)
 }
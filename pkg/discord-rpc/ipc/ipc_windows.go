@@ -1,22 +1,40 @@
+//go:build windows
 // +build windows
 
 package ipc
 
 import (
-	npipe "gopkg.in/natefinch/npipe.v2"
+	"context"
+	"fmt"
+	"net"
 	"time"
+
+	npipe "gopkg.in/natefinch/npipe.v2"
 )
 
-// OpenSocket opens the discord-ipc-0 named pipe
-func OpenSocket() error {
-	// Connect to the Windows named pipe, this is a well known name
+// Candidates returns the well known names of the discord-ipc-0 to
+// discord-ipc-9 named pipes. Pipes cannot be checked without connecting, so
+// all of them are returned
+func Candidates() []string {
+	paths := make([]string, 10)
+	for i := range paths {
+		paths[i] = fmt.Sprintf(`\\.\pipe\discord-ipc-%d`, i)
+	}
+	return paths
+}
+
+// DefaultPath returns the well known name of the discord-ipc-0 named pipe
+func DefaultPath() string {
+	return `\\.\pipe\discord-ipc-0`
+}
+
+// Dial connects to the named pipe at path
+func Dial(ctx context.Context, path string) (net.Conn, error) {
 	// We use DialTimeout since it will block forever (or very very long) on Windows
 	// if the pipe is not available (Discord not running)
-	sock, err := npipe.DialTimeout(`\\.\pipe\discord-ipc-0`, time.Second*2)
-	if err != nil {
-		return err
+	timeout := time.Second * 2
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
 	}
-
-	socket = sock
-	return nil
+	return npipe.DialTimeout(path, timeout)
 }
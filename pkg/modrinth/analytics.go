@@ -0,0 +1,254 @@
+package modrinth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Amount is a sum of money in USD. The API sends amounts as decimal strings
+// or as numbers, and both decode into it.
+type Amount float64
+
+// UnmarshalJSON decodes the amount from a JSON number or decimal string.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
+	f, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return err
+	}
+	*a = Amount(f)
+	return nil
+}
+
+// MarshalJSON encodes the amount as a decimal string like the API does.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatFloat(float64(a), 'f', -1, 64))
+}
+
+// AnalyticsOptions represents the options for the analytics endpoints.
+type AnalyticsOptions struct {
+	// ProjectIDs are the projects to fetch analytics for. Empty means every
+	// project of the authenticated user.
+	ProjectIDs []string
+	// VersionIDs are the versions to fetch download analytics for. The
+	// result is keyed by version ID instead of project ID when set.
+	VersionIDs []string
+	// Start and End bound the time range. The API defaults to the last two weeks.
+	Start time.Time
+	End   time.Time
+	// Resolution is the width of one bucket, rounded down to whole minutes.
+	// The API defaults to one day.
+	Resolution time.Duration
+}
+
+func (o AnalyticsOptions) query() url.Values {
+	v := url.Values{}
+	if len(o.ProjectIDs) > 0 {
+		b, _ := json.Marshal(o.ProjectIDs)
+		v.Add("project_ids", string(b))
+	}
+	if len(o.VersionIDs) > 0 {
+		b, _ := json.Marshal(o.VersionIDs)
+		v.Add("version_ids", string(b))
+	}
+	if !o.Start.IsZero() {
+		v.Add("start_date", o.Start.UTC().Format(time.RFC3339))
+	}
+	if !o.End.IsZero() {
+		v.Add("end_date", o.End.UTC().Format(time.RFC3339))
+	}
+	if minutes := int(o.Resolution / time.Minute); minutes > 0 {
+		v.Add("resolution_minutes", strconv.Itoa(minutes))
+	}
+	return v
+}
+
+// Analytics maps a project or version ID to a count per bucket. Buckets are
+// keyed by their Unix start time in seconds.
+type Analytics map[string]map[int64]int
+
+// RevenueAnalytics maps a project ID to the revenue per bucket. Buckets are
+// keyed by their Unix start time in seconds.
+type RevenueAnalytics map[string]map[int64]Amount
+
+// CountryAnalytics maps a project ID to a count per country code.
+type CountryAnalytics map[string]map[string]int
+
+// Totals sums the counts of every project per country code.
+func (a CountryAnalytics) Totals() map[string]int {
+	totals := map[string]int{}
+	for _, countries := range a {
+		for country, count := range countries {
+			totals[country] += count
+		}
+	}
+	return totals
+}
+
+func (c *ModrinthV2Client) analytics(ctx context.Context, endpoint string, options AnalyticsOptions, result any) error {
+	path := "/v3/analytics/" + endpoint
+	if v := options.query(); len(v) > 0 {
+		path += "?" + v.Encode()
+	}
+	return c.doJSON(ctx, http.MethodGet, path, nil, result)
+}
+
+// GetDownloadAnalytics fetches the downloads of projects or versions over time.
+// It requires authentication.
+func (c *ModrinthV2Client) GetDownloadAnalytics(ctx context.Context, options AnalyticsOptions) (Analytics, error) {
+	var result Analytics
+	err := c.analytics(ctx, "downloads", options, &result)
+	return result, err
+}
+
+// GetViewAnalytics fetches the page views of projects over time.
+// It requires authentication.
+func (c *ModrinthV2Client) GetViewAnalytics(ctx context.Context, options AnalyticsOptions) (Analytics, error) {
+	var result Analytics
+	err := c.analytics(ctx, "views", options, &result)
+	return result, err
+}
+
+// GetRevenueAnalytics fetches the revenue of projects over time.
+// It requires authentication.
+func (c *ModrinthV2Client) GetRevenueAnalytics(ctx context.Context, options AnalyticsOptions) (RevenueAnalytics, error) {
+	var result RevenueAnalytics
+	err := c.analytics(ctx, "revenue", options, &result)
+	return result, err
+}
+
+// GetCountryDownloadAnalytics fetches the downloads of projects per country.
+// It requires authentication.
+func (c *ModrinthV2Client) GetCountryDownloadAnalytics(ctx context.Context, options AnalyticsOptions) (CountryAnalytics, error) {
+	var result CountryAnalytics
+	err := c.analytics(ctx, "countries/downloads", options, &result)
+	return result, err
+}
+
+// GetCountryViewAnalytics fetches the page views of projects per country.
+// It requires authentication.
+func (c *ModrinthV2Client) GetCountryViewAnalytics(ctx context.Context, options AnalyticsOptions) (CountryAnalytics, error) {
+	var result CountryAnalytics
+	err := c.analytics(ctx, "countries/views", options, &result)
+	return result, err
+}
+
+// GetPayoutBalance fetches the payout balance of the authenticated user.
+func (c *ModrinthV2Client) GetPayoutBalance(ctx context.Context) (*PayoutBalance, error) {
+	path := "/v3/payout/balance"
+	var balance PayoutBalance
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &balance)
+	return &balance, err
+}
+
+// GetPayouts fetches the withdrawal history of the authenticated user.
+func (c *ModrinthV2Client) GetPayouts(ctx context.Context) ([]Payout, error) {
+	path := "/v3/payout"
+	var payouts []Payout
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &payouts)
+	return payouts, err
+}
+
+// DataPoint is the value of one bucket of a time series.
+type DataPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// TimeSeries is the values of one project or version over time, oldest first.
+type TimeSeries struct {
+	ID     string
+	Points []DataPoint
+}
+
+// Total returns the sum of the values.
+func (s TimeSeries) Total() float64 {
+	var total float64
+	for _, p := range s.Points {
+		total += p.Value
+	}
+	return total
+}
+
+// NewTimeSeries turns an analytics response into a time series per project or
+// version, sorted by ID. With a positive resolution, buckets missing between
+// the first and last one of a series are filled with zero.
+func NewTimeSeries[V int | Amount](data map[string]map[int64]V, resolution time.Duration) []TimeSeries {
+	series := make([]TimeSeries, 0, len(data))
+	for id, buckets := range data {
+		values := make(map[int64]float64, len(buckets))
+		for t, v := range buckets {
+			values[t] = float64(v)
+		}
+		series = append(series, TimeSeries{ID: id, Points: points(values, resolution)})
+	}
+	slices.SortFunc(series, func(a, b TimeSeries) int { return strings.Compare(a.ID, b.ID) })
+	return series
+}
+
+// MergeTimeSeries sums series into one with the ID, for example the versions
+// of a project or every project of an author.
+func MergeTimeSeries(id string, resolution time.Duration, series ...TimeSeries) TimeSeries {
+	values := map[int64]float64{}
+	for _, s := range series {
+		for _, p := range s.Points {
+			values[p.Time.Unix()] += p.Value
+		}
+	}
+	return TimeSeries{ID: id, Points: points(values, resolution)}
+}
+
+// GroupTimeSeries merges the series whose IDs map to the same group, such as
+// version series grouped by their project ID. Series that map to an empty
+// group are dropped.
+func GroupTimeSeries(series []TimeSeries, resolution time.Duration, group func(id string) string) []TimeSeries {
+	groups := map[string][]TimeSeries{}
+	for _, s := range series {
+		if g := group(s.ID); g != "" {
+			groups[g] = append(groups[g], s)
+		}
+	}
+	result := make([]TimeSeries, 0, len(groups))
+	for g, members := range groups {
+		result = append(result, MergeTimeSeries(g, resolution, members...))
+	}
+	slices.SortFunc(result, func(a, b TimeSeries) int { return strings.Compare(a.ID, b.ID) })
+	return result
+}
+
+func points(values map[int64]float64, resolution time.Duration) []DataPoint {
+	if len(values) == 0 {
+		return nil
+	}
+	times := make([]int64, 0, len(values))
+	for t := range values {
+		times = append(times, t)
+	}
+	slices.Sort(times)
+	step := int64(resolution / time.Second)
+	var result []DataPoint
+	for i, t := range times {
+		if step > 0 && i > 0 {
+			for gap := times[i-1] + step; gap < t; gap += step {
+				result = append(result, DataPoint{Time: time.Unix(gap, 0).UTC()})
+			}
+		}
+		result = append(result, DataPoint{Time: time.Unix(t, 0).UTC(), Value: values[t]})
+	}
+	return result
+}
@@ -0,0 +1,150 @@
+package modrinth_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+var fixtures = map[string]string{
+	"/v3/analytics/downloads":           "testdata/analytics/downloads.json",
+	"/v3/analytics/views":               "testdata/analytics/views.json",
+	"/v3/analytics/revenue":             "testdata/analytics/revenue.json",
+	"/v3/analytics/countries/downloads": "testdata/analytics/countries.json",
+	"/v3/payout/balance":                "testdata/analytics/balance.json",
+	"/v3/payout":                        "testdata/analytics/payouts.json",
+}
+
+func setupFixtureServer(t *testing.T, queries map[string]string) *modrinth.ModrinthV2Client {
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		file, ok := fixtures[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if expected, ok := queries[r.URL.Path]; ok && r.URL.RawQuery != expected {
+			t.Errorf("%s query = %s, want %s", r.URL.Path, r.URL.RawQuery, expected)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	})
+	t.Cleanup(server.Close)
+	return client
+}
+
+func day(n int) time.Time {
+	return time.Date(2024, 1, 1+n, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAnalytics(t *testing.T) {
+	client := setupFixtureServer(t, map[string]string{
+		"/v3/analytics/downloads": "end_date=2024-01-08T00%3A00%3A00Z&resolution_minutes=1440&start_date=2024-01-01T00%3A00%3A00Z&version_ids=%5B%22IIJJKKLL%22%2C%22MMNNOOPP%22%5D",
+		"/v3/analytics/views":     "project_ids=%5B%22AABBCCDD%22%5D",
+	})
+	ctx := context.Background()
+
+	downloads, err := client.GetDownloadAnalytics(ctx, modrinth.AnalyticsOptions{
+		VersionIDs: []string{"IIJJKKLL", "MMNNOOPP"},
+		Start:      day(0),
+		End:        day(7),
+		Resolution: 24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("GetDownloadAnalytics() error = %v", err)
+	}
+	if downloads["IIJJKKLL"][day(1).Unix()] != 95 {
+		t.Errorf("GetDownloadAnalytics() = %v", downloads)
+	}
+
+	views, err := client.GetViewAnalytics(ctx, modrinth.AnalyticsOptions{ProjectIDs: []string{"AABBCCDD"}})
+	if err != nil || len(views["AABBCCDD"]) != 2 {
+		t.Errorf("GetViewAnalytics() = %v, %v", views, err)
+	}
+
+	revenue, err := client.GetRevenueAnalytics(ctx, modrinth.AnalyticsOptions{})
+	if err != nil {
+		t.Fatalf("GetRevenueAnalytics() error = %v", err)
+	}
+	if revenue["AABBCCDD"][day(1).Unix()] != 0.7512 || revenue["EEFFGGHH"][day(0).Unix()] != 0.5 {
+		t.Errorf("GetRevenueAnalytics() = %v", revenue)
+	}
+
+	countries, err := client.GetCountryDownloadAnalytics(ctx, modrinth.AnalyticsOptions{})
+	if err != nil {
+		t.Fatalf("GetCountryDownloadAnalytics() error = %v", err)
+	}
+	if expected := map[string]int{"US": 320, "DE": 120, "BR": 30, "JP": 4}; !reflect.DeepEqual(countries.Totals(), expected) {
+		t.Errorf("Totals() = %v, want %v", countries.Totals(), expected)
+	}
+
+	if _, err := client.GetCountryViewAnalytics(ctx, modrinth.AnalyticsOptions{}); err == nil {
+		t.Error("GetCountryViewAnalytics() of a missing fixture succeeded")
+	}
+}
+
+func TestPayouts(t *testing.T) {
+	client := setupFixtureServer(t, nil)
+	ctx := context.Background()
+
+	balance, err := client.GetPayoutBalance(ctx)
+	if err != nil {
+		t.Fatalf("GetPayoutBalance() error = %v", err)
+	}
+	if balance.Available != 42.17 || balance.Pending != 3.8 || balance.WithdrawnLifetime != 100 || balance.WithdrawnYTD != 25.5 || balance.Dates["2024-02-01T00:00:00Z"] != 3.8 {
+		t.Errorf("GetPayoutBalance() = %+v", balance)
+	}
+
+	payouts, err := client.GetPayouts(ctx)
+	if err != nil {
+		t.Fatalf("GetPayouts() error = %v", err)
+	}
+	if len(payouts) != 2 || payouts[0].Amount != 25.5 || payouts[0].Fee != 0.25 || payouts[1].Status != "in-transit" || payouts[1].Fee != 0 {
+		t.Errorf("GetPayouts() = %+v", payouts)
+	}
+}
+
+func TestTimeSeries(t *testing.T) {
+	client := setupFixtureServer(t, nil)
+	ctx := context.Background()
+	downloads, err := client.GetDownloadAnalytics(ctx, modrinth.AnalyticsOptions{VersionIDs: []string{"IIJJKKLL", "MMNNOOPP"}})
+	if err != nil {
+		t.Fatalf("GetDownloadAnalytics() error = %v", err)
+	}
+
+	series := modrinth.NewTimeSeries(downloads, 24*time.Hour)
+	expected := []modrinth.TimeSeries{
+		{ID: "IIJJKKLL", Points: []modrinth.DataPoint{{day(0), 120}, {day(1), 95}, {day(2), 0}, {day(3), 40}}},
+		{ID: "MMNNOOPP", Points: []modrinth.DataPoint{{day(0), 10}, {day(1), 0}, {day(2), 7}}},
+	}
+	if !reflect.DeepEqual(series, expected) {
+		t.Errorf("NewTimeSeries() = %v, want %v", series, expected)
+	}
+	if series[0].Total() != 255 {
+		t.Errorf("Total() = %v, want 255", series[0].Total())
+	}
+
+	projects := modrinth.GroupTimeSeries(series, 24*time.Hour, func(id string) string { return "AABBCCDD" })
+	expectedProject := []modrinth.TimeSeries{
+		{ID: "AABBCCDD", Points: []modrinth.DataPoint{{day(0), 130}, {day(1), 95}, {day(2), 7}, {day(3), 40}}},
+	}
+	if !reflect.DeepEqual(projects, expectedProject) {
+		t.Errorf("GroupTimeSeries() = %v, want %v", projects, expectedProject)
+	}
+
+	revenue, err := client.GetRevenueAnalytics(ctx, modrinth.AnalyticsOptions{})
+	if err != nil {
+		t.Fatalf("GetRevenueAnalytics() error = %v", err)
+	}
+	total := modrinth.MergeTimeSeries("total", 0, modrinth.NewTimeSeries(revenue, 0)...)
+	if len(total.Points) != 2 || total.Points[0].Value != 1.75 {
+		t.Errorf("MergeTimeSeries() = %v", total)
+	}
+}
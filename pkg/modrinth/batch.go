@@ -0,0 +1,316 @@
+package modrinth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultBatchChunkSize   = 100
+	defaultBatchConcurrency = 4
+)
+
+// BatchOptions defines options for chunked batch requests.
+type BatchOptions struct {
+	// ChunkSize is the maximum number of keys sent in one request. Defaults to 100.
+	ChunkSize int
+	// Concurrency is the maximum number of chunks requested at once. Defaults to 4.
+	Concurrency int
+}
+
+func (o BatchOptions) normalize() BatchOptions {
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = defaultBatchChunkSize
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultBatchConcurrency
+	}
+	return o
+}
+
+// ChunkError represents the failure of a single chunk of a batch request.
+type ChunkError struct {
+	Index int
+	Keys  []string
+	Err   error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("chunk %d (%d keys): %v", e.Index, len(e.Keys), e.Err)
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError is returned alongside partial results when some chunks of a batch request fail.
+type BatchError struct {
+	Chunks []*ChunkError
+}
+
+func (e *BatchError) Error() string {
+	msgs := make([]string, len(e.Chunks))
+	for i, c := range e.Chunks {
+		msgs[i] = c.Error()
+	}
+	return fmt.Sprintf("%d batch chunk(s) failed: %s", len(e.Chunks), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of all failed chunks.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Chunks))
+	for i, c := range e.Chunks {
+		errs[i] = c
+	}
+	return errs
+}
+
+// GetProjectsBatch fetches projects by IDs or slugs in chunks.
+// The result follows the order of the first occurrence of each key; projects
+// the API does not return are omitted. If some chunks fail, the projects of the
+// successful chunks are returned together with a *BatchError.
+func (c *ModrinthV2Client) GetProjectsBatch(ctx context.Context, projectIDs []string, options BatchOptions) ([]Project, error) {
+	keys := uniqueKeys(projectIDs)
+	found := make(map[string]Project, len(keys))
+	var mu sync.Mutex
+	err := runBatch(ctx, keys, options, func(ctx context.Context, chunk []string) error {
+		var projects []Project
+		if err := c.doJSONShared(ctx, http.MethodGet, idsPath("/v2/projects", chunk), nil, &projects); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, p := range projects {
+			found[p.ID] = p
+			if p.Slug != "" {
+				// Slugs are case-insensitive, unlike IDs.
+				found[strings.ToLower(p.Slug)] = p
+			}
+		}
+		return nil
+	})
+	result := make([]Project, 0, len(found))
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		p, ok := found[k]
+		if !ok {
+			p, ok = found[strings.ToLower(k)]
+		}
+		if !ok || seen[p.ID] {
+			continue
+		}
+		seen[p.ID] = true
+		result = append(result, p)
+	}
+	return result, err
+}
+
+// GetProjectVersionsByIDBatch fetches project versions by IDs in chunks.
+// The result follows the order of the first occurrence of each ID; versions
+// the API does not return are omitted. If some chunks fail, the versions of the
+// successful chunks are returned together with a *BatchError.
+func (c *ModrinthV2Client) GetProjectVersionsByIDBatch(ctx context.Context, ids []string, options BatchOptions) ([]ProjectVersion, error) {
+	keys := uniqueKeys(ids)
+	found := make(map[string]ProjectVersion, len(keys))
+	var mu sync.Mutex
+	err := runBatch(ctx, keys, options, func(ctx context.Context, chunk []string) error {
+		var versions []ProjectVersion
+		if err := c.doJSONShared(ctx, http.MethodGet, idsPath("/v2/versions", chunk), nil, &versions); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, ver := range versions {
+			found[ver.ID] = ver
+		}
+		return nil
+	})
+	result := make([]ProjectVersion, 0, len(found))
+	for _, k := range keys {
+		if ver, ok := found[k]; ok {
+			result = append(result, ver)
+		}
+	}
+	return result, err
+}
+
+// GetProjectVersionsByHashBatch fetches project versions by file hashes in chunks.
+// If some chunks fail, the versions of the successful chunks are returned
+// together with a *BatchError.
+func (c *ModrinthV2Client) GetProjectVersionsByHashBatch(ctx context.Context, hashes []string, algorithm string, options BatchOptions) (map[string]ProjectVersion, error) {
+	if algorithm == "" {
+		algorithm = "sha1"
+	}
+	keys := uniqueKeys(hashes)
+	result := make(map[string]ProjectVersion, len(keys))
+	var mu sync.Mutex
+	err := runBatch(ctx, keys, options, func(ctx context.Context, chunk []string) error {
+		body := map[string]any{"hashes": chunk, "algorithm": algorithm}
+		var versions map[string]ProjectVersion
+		if err := c.doJSONShared(ctx, http.MethodPost, "/v2/version_files", body, &versions); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for h, ver := range versions {
+			result[h] = ver
+		}
+		return nil
+	})
+	return result, err
+}
+
+func uniqueKeys(keys []string) []string {
+	seen := make(map[string]bool, len(keys))
+	result := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		result = append(result, k)
+	}
+	return result
+}
+
+// runBatch splits keys into chunks and runs fn for each chunk with bounded concurrency.
+func runBatch(ctx context.Context, keys []string, options BatchOptions, fn func(ctx context.Context, chunk []string) error) error {
+	options = options.normalize()
+	var chunks [][]string
+	for start := 0; start < len(keys); start += options.ChunkSize {
+		end := min(start+options.ChunkSize, len(keys))
+		chunks = append(chunks, keys[start:end])
+	}
+
+	errs := make([]*ChunkError, len(chunks))
+	sem := make(chan struct{}, options.Concurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = &ChunkError{Index: i, Keys: chunk, Err: ctx.Err()}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, chunk); err != nil {
+				errs[i] = &ChunkError{Index: i, Keys: chunk, Err: err}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var failed []*ChunkError
+	for _, e := range errs {
+		if e != nil {
+			failed = append(failed, e)
+		}
+	}
+	if len(failed) > 0 {
+		return &BatchError{Chunks: failed}
+	}
+	return nil
+}
+
+// doJSONShared behaves like doJSON, but identical requests that are in flight
+// at the same time share a single HTTP round trip.
+func (c *ModrinthV2Client) doJSONShared(ctx context.Context, method, path string, body any, result any) error {
+	key := method + " " + path
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		key += " " + string(b)
+	}
+	data, err := c.flights.do(ctx, key, func(ctx context.Context) ([]byte, error) {
+		resp, err := c.send(ctx, method, path, body)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return io.ReadAll(resp.Body)
+	})
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		if err := json.Unmarshal(data, result); err != nil {
+			return err
+		}
+		return c.checkStrict(result)
+	}
+	return nil
+}
+
+type flightCall struct {
+	done chan struct{}
+	data []byte
+	err  error
+
+	// waiters counts the callers still waiting; the last one to give up
+	// cancels the shared request.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// flightGroup deduplicates concurrent calls with the same key.
+// The zero value is ready to use.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// do runs fn once for all concurrent callers with the same key. fn gets a
+// context that is only cancelled once every caller's ctx is done, and each
+// caller stops waiting as soon as its own ctx is done.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call, ok := g.calls[key]
+	if ok {
+		call.waiters++
+	} else {
+		shared, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.calls[key] = call
+		go func() {
+			defer cancel()
+			call.data, call.err = fn(shared)
+			g.mu.Lock()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+			g.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.data, call.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			// Later callers start a fresh request instead of joining a cancelled one
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,200 @@
+package modrinth_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+func TestGetProjectsBatch(t *testing.T) {
+	var requests int32
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var ids []string
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("ids")), &ids); err != nil {
+			w.WriteHeader(400)
+			return
+		}
+		var projects []modrinth.Project
+		// Respond in reverse order to check that the input order is restored.
+		for i := len(ids) - 1; i >= 0; i-- {
+			if ids[i] == "broken" {
+				w.WriteHeader(500)
+				fmt.Fprint(w, "Internal error")
+				return
+			}
+			if ids[i] == "missing" {
+				continue
+			}
+			projects = append(projects, modrinth.Project{ID: ids[i], Slug: "slug-" + ids[i]})
+		}
+		json.NewEncoder(w).Encode(projects)
+	})
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		ids      []string
+		expected []modrinth.Project
+		requests int32
+		wantErr  bool
+	}{
+		{
+			name: "Successful chunked fetch",
+			ids:  []string{"a", "b", "c", "a", "missing", "d", "e"},
+			expected: []modrinth.Project{
+				{ID: "a", Slug: "slug-a"},
+				{ID: "b", Slug: "slug-b"},
+				{ID: "c", Slug: "slug-c"},
+				{ID: "d", Slug: "slug-d"},
+				{ID: "e", Slug: "slug-e"},
+			},
+			requests: 3,
+		},
+		{
+			name: "Partial failure",
+			ids:  []string{"a", "b", "broken", "c"},
+			expected: []modrinth.Project{
+				{ID: "a", Slug: "slug-a"},
+				{ID: "b", Slug: "slug-b"},
+			},
+			requests: 2,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			result, err := client.GetProjectsBatch(context.Background(), tt.ids, modrinth.BatchOptions{ChunkSize: 2, Concurrency: 2})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetProjectsBatch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				var batchErr *modrinth.BatchError
+				if !errors.As(err, &batchErr) || len(batchErr.Chunks) != 1 {
+					t.Errorf("GetProjectsBatch() error = %v, want one failed chunk", err)
+				}
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("GetProjectsBatch() = %v, want %v", result, tt.expected)
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.requests {
+				t.Errorf("GetProjectsBatch() sent %d requests, want %d", got, tt.requests)
+			}
+		})
+	}
+}
+
+func TestGetProjectVersionsByHashBatch(t *testing.T) {
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Hashes    []string `json:"hashes"`
+			Algorithm string   `json:"algorithm"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Algorithm != "sha1" {
+			w.WriteHeader(400)
+			return
+		}
+		versions := map[string]modrinth.ProjectVersion{}
+		for _, h := range body.Hashes {
+			versions[h] = modrinth.ProjectVersion{ID: "v-" + h}
+		}
+		json.NewEncoder(w).Encode(versions)
+	})
+	defer server.Close()
+
+	result, err := client.GetProjectVersionsByHashBatch(context.Background(), []string{"h1", "h2", "h3"}, "", modrinth.BatchOptions{ChunkSize: 2})
+	if err != nil {
+		t.Fatalf("GetProjectVersionsByHashBatch() error = %v", err)
+	}
+	expected := map[string]modrinth.ProjectVersion{
+		"h1": {ID: "v-h1"},
+		"h2": {ID: "v-h2"},
+		"h3": {ID: "v-h3"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("GetProjectVersionsByHashBatch() = %v, want %v", result, expected)
+	}
+}
+
+func TestGetProjectsBatchSharedCancel(t *testing.T) {
+	var requests int32
+	received := make(chan struct{}, 2)
+	release := make(chan struct{})
+	cancelled := make(chan struct{}, 2)
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		received <- struct{}{}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			cancelled <- struct{}{}
+			return
+		}
+		json.NewEncoder(w).Encode([]modrinth.Project{{ID: "a"}})
+	})
+	defer server.Close()
+	options := modrinth.BatchOptions{ChunkSize: 1, Concurrency: 1}
+
+	// The first caller starts the shared request and then gives up
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := client.GetProjectsBatch(ctx, []string{"a"}, options)
+		first <- err
+	}()
+	<-received
+
+	second := make(chan []modrinth.Project, 1)
+	go func() {
+		result, err := client.GetProjectsBatch(context.Background(), []string{"a"}, options)
+		if err != nil {
+			t.Errorf("GetProjectsBatch() error = %v", err)
+		}
+		second <- result
+	}()
+	// Give the second caller time to join the request in flight
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled GetProjectsBatch() error = %v, want context.Canceled", err)
+	}
+	close(release)
+	if result := <-second; len(result) != 1 || result[0].ID != "a" {
+		t.Errorf("GetProjectsBatch() = %v, want project a", result)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("sent %d requests, want 1", got)
+	}
+	select {
+	case <-cancelled:
+		t.Error("the shared request was cancelled with the first caller")
+	default:
+	}
+}
+
+func TestGetProjectsBatchSlugCase(t *testing.T) {
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]modrinth.Project{{ID: "AANobbMI", Slug: "sodium"}})
+	})
+	defer server.Close()
+
+	result, err := client.GetProjectsBatch(context.Background(), []string{"Sodium", "SODIUM"}, modrinth.BatchOptions{})
+	if err != nil {
+		t.Fatalf("GetProjectsBatch() error = %v", err)
+	}
+	if len(result) != 1 || result[0].ID != "AANobbMI" {
+		t.Errorf("GetProjectsBatch() = %v, want sodium once", result)
+	}
+}
@@ -0,0 +1,249 @@
+// Package changelog assembles the changelogs of every version between two
+// versions of a project, and of every mod changed between two modpack revisions.
+package changelog
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+// Options filters the intermediate versions.
+type Options struct {
+	// Loaders keeps only versions supporting one of the loaders. Empty keeps all.
+	Loaders []string
+	// GameVersions keeps only versions supporting one of the game versions. Empty keeps all.
+	GameVersions []string
+	// VersionTypes keeps only versions of the given types. Empty keeps all.
+	VersionTypes []string
+}
+
+func (o Options) accepts(v *modrinth.ProjectVersion) bool {
+	if len(o.VersionTypes) > 0 && !slices.Contains(o.VersionTypes, v.VersionType) {
+		return false
+	}
+	if len(o.Loaders) > 0 && !slices.ContainsFunc(v.Loaders, func(l string) bool { return slices.Contains(o.Loaders, l) }) {
+		return false
+	}
+	if len(o.GameVersions) > 0 && !slices.ContainsFunc(v.GameVersions, func(g string) bool { return slices.Contains(o.GameVersions, g) }) {
+		return false
+	}
+	return true
+}
+
+// Changelog lists the versions of a project after From up to and including To.
+type Changelog struct {
+	Project modrinth.Project
+	From    modrinth.ProjectVersion
+	To      modrinth.ProjectVersion
+	// Versions are ordered from oldest to newest.
+	Versions []modrinth.ProjectVersion
+	// Downgrade is set when To was published before From. Versions is empty then.
+	Downgrade bool
+}
+
+func published(v *modrinth.ProjectVersion) time.Time {
+	t, _ := v.Published()
+	return t
+}
+
+// Select returns the versions published after from and up to and including to
+// that pass the filters, ordered from oldest to newest. The to version is
+// always included.
+func Select(versions []modrinth.ProjectVersion, from, to *modrinth.ProjectVersion, options Options) []modrinth.ProjectVersion {
+	start, end := published(from), published(to)
+	var result []modrinth.ProjectVersion
+	for i := range versions {
+		v := &versions[i]
+		if v.ID == from.ID {
+			continue
+		}
+		if v.ID != to.ID {
+			t := published(v)
+			if !t.After(start) || t.After(end) || !options.accepts(v) {
+				continue
+			}
+		}
+		result = append(result, *v)
+	}
+	if !slices.ContainsFunc(result, func(v modrinth.ProjectVersion) bool { return v.ID == to.ID }) {
+		result = append(result, *to)
+	}
+	slices.SortStableFunc(result, func(a, b modrinth.ProjectVersion) int {
+		return published(&a).Compare(published(&b))
+	})
+	return result
+}
+
+// Between fetches the versions of the project and builds the changelog from
+// one version to another.
+func Between(ctx context.Context, client *modrinth.ModrinthV2Client, project *modrinth.Project, from, to *modrinth.ProjectVersion, options Options) (*Changelog, error) {
+	c := &Changelog{Project: *project, From: *from, To: *to}
+	if published(to).Before(published(from)) {
+		c.Downgrade = true
+		return c, nil
+	}
+	versions, err := client.GetProjectVersions(ctx, project.ID, modrinth.GetProjectVersionsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	c.Versions = Select(versions, from, to, options)
+	return c, nil
+}
+
+// Markdown renders the changelog as one Markdown document, oldest version first.
+func (c *Changelog) Markdown() string {
+	var sb strings.Builder
+	c.writeMarkdown(&sb, "#")
+	return sb.String()
+}
+
+func (c *Changelog) writeMarkdown(sb *strings.Builder, heading string) {
+	title := projectTitle(&c.Project, &c.From)
+	fmt.Fprintf(sb, "%s %s %s → %s\n\n", heading, title, c.From.VersionNumber, c.To.VersionNumber)
+	if c.Downgrade {
+		sb.WriteString("Downgraded, no changelog.\n\n")
+		return
+	}
+	for _, v := range c.Versions {
+		fmt.Fprintf(sb, "%s# %s", heading, v.VersionNumber)
+		if t := published(&v); !t.IsZero() {
+			fmt.Fprintf(sb, " (%s)", t.Format(time.DateOnly))
+		}
+		sb.WriteString("\n\n")
+		if changelog := strings.TrimSpace(v.Changelog); changelog != "" {
+			sb.WriteString(changelog)
+		} else {
+			sb.WriteString("_No changelog provided._")
+		}
+		sb.WriteString("\n\n")
+	}
+}
+
+// projectTitle returns the title of the project, falling back to its slug and
+// then to the version name when the project could not be fetched.
+func projectTitle(project *modrinth.Project, version *modrinth.ProjectVersion) string {
+	for _, title := range []string{project.Title, project.Slug, version.Name, version.ProjectID} {
+		if title != "" {
+			return title
+		}
+	}
+	return ""
+}
+
+// Mod is a mod of a modpack revision.
+type Mod struct {
+	// Project is empty when the project no longer exists.
+	Project modrinth.Project
+	Version modrinth.ProjectVersion
+}
+
+// PackReport lists the mods added, removed and updated between two modpack revisions.
+type PackReport struct {
+	Added   []Mod
+	Removed []Mod
+	Updated []*Changelog
+}
+
+// CompareRevisions builds the changelog of every mod changed between two
+// modpack revisions, each given as the versions of its mods.
+func CompareRevisions(ctx context.Context, client *modrinth.ModrinthV2Client, previous, next []modrinth.ProjectVersion, options Options) (*PackReport, error) {
+	before := make(map[string]modrinth.ProjectVersion, len(previous))
+	for _, v := range previous {
+		before[v.ProjectID] = v
+	}
+	after := make(map[string]modrinth.ProjectVersion, len(next))
+	for _, v := range next {
+		after[v.ProjectID] = v
+	}
+
+	report := &PackReport{}
+	var added, removed, changed []string
+	for _, v := range next {
+		old, ok := before[v.ProjectID]
+		switch {
+		case !ok:
+			added = append(added, v.ProjectID)
+		case old.ID != v.ID:
+			changed = append(changed, v.ProjectID)
+		}
+	}
+	for _, v := range previous {
+		if _, ok := after[v.ProjectID]; !ok {
+			removed = append(removed, v.ProjectID)
+		}
+	}
+	if len(added)+len(removed)+len(changed) == 0 {
+		return report, nil
+	}
+
+	projects, err := client.GetProjectsBatch(ctx, slices.Concat(added, removed, changed), modrinth.BatchOptions{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*modrinth.Project, len(projects))
+	for i := range projects {
+		byID[projects[i].ID] = &projects[i]
+	}
+	for _, id := range added {
+		report.Added = append(report.Added, newMod(byID[id], after[id]))
+	}
+	for _, id := range removed {
+		report.Removed = append(report.Removed, newMod(byID[id], before[id]))
+	}
+	for _, id := range changed {
+		p, ok := byID[id]
+		if !ok {
+			continue
+		}
+		from, to := before[id], after[id]
+		c, err := Between(ctx, client, p, &from, &to, options)
+		if err != nil {
+			return nil, fmt.Errorf("changelog of %s: %w", p.ID, err)
+		}
+		report.Updated = append(report.Updated, c)
+	}
+	slices.SortFunc(report.Updated, func(a, b *Changelog) int {
+		return cmp.Compare(strings.ToLower(a.Project.Title), strings.ToLower(b.Project.Title))
+	})
+	return report, nil
+}
+
+func newMod(project *modrinth.Project, version modrinth.ProjectVersion) Mod {
+	m := Mod{Version: version}
+	if project != nil {
+		m.Project = *project
+	}
+	return m
+}
+
+// Markdown renders the report as one Markdown document.
+func (r *PackReport) Markdown() string {
+	var sb strings.Builder
+	if len(r.Added) > 0 {
+		sb.WriteString("# Added\n\n")
+		for _, m := range r.Added {
+			fmt.Fprintf(&sb, "- %s %s\n", projectTitle(&m.Project, &m.Version), m.Version.VersionNumber)
+		}
+		sb.WriteString("\n")
+	}
+	if len(r.Removed) > 0 {
+		sb.WriteString("# Removed\n\n")
+		for _, m := range r.Removed {
+			fmt.Fprintf(&sb, "- %s %s\n", projectTitle(&m.Project, &m.Version), m.Version.VersionNumber)
+		}
+		sb.WriteString("\n")
+	}
+	if len(r.Updated) > 0 {
+		sb.WriteString("# Updated\n\n")
+		for _, c := range r.Updated {
+			c.writeMarkdown(&sb, "##")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
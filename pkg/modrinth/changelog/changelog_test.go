@@ -0,0 +1,104 @@
+package changelog_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth/changelog"
+)
+
+var versions = []modrinth.ProjectVersion{
+	{ID: "v4", ProjectID: "AAAA", VersionNumber: "1.3.0", VersionType: "release", Loaders: []string{"fabric"}, DatePublished: "2024-04-01T00:00:00Z", Changelog: "Added widgets"},
+	{ID: "v3f", ProjectID: "AAAA", VersionNumber: "1.2.1-forge", VersionType: "release", Loaders: []string{"forge"}, DatePublished: "2024-03-15T00:00:00Z", Changelog: "Forge port"},
+	{ID: "v3", ProjectID: "AAAA", VersionNumber: "1.2.1-beta", VersionType: "beta", Loaders: []string{"fabric"}, DatePublished: "2024-03-01T00:00:00Z", Changelog: "Beta fixes"},
+	{ID: "v2", ProjectID: "AAAA", VersionNumber: "1.2.0", VersionType: "release", Loaders: []string{"fabric"}, DatePublished: "2024-02-01T00:00:00Z"},
+	{ID: "v1", ProjectID: "AAAA", VersionNumber: "1.1.0", VersionType: "release", Loaders: []string{"fabric"}, DatePublished: "2024-01-01T00:00:00Z", Changelog: "Initial"},
+}
+
+func ids(versions []modrinth.ProjectVersion) []string {
+	var result []string
+	for _, v := range versions {
+		result = append(result, v.ID)
+	}
+	return result
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  changelog.Options
+		expected []string
+	}{
+		{name: "No filters", options: changelog.Options{}, expected: []string{"v2", "v3", "v3f", "v4"}},
+		{name: "Loader filter", options: changelog.Options{Loaders: []string{"fabric"}}, expected: []string{"v2", "v3", "v4"}},
+		{name: "Release only", options: changelog.Options{Loaders: []string{"fabric"}, VersionTypes: []string{"release"}}, expected: []string{"v2", "v4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := changelog.Select(versions, &versions[4], &versions[0], tt.options)
+			if got := ids(result); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Select() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompareRevisions(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v2/projects", func(w http.ResponseWriter, r *http.Request) {
+		// BBBB was deleted since the previous revision
+		json.NewEncoder(w).Encode([]modrinth.Project{{ID: "AAAA", Title: "Mod A"}, {ID: "CCCC", Title: "Mod C"}})
+	})
+	mux.HandleFunc("GET /v2/project/AAAA/version", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(versions)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := modrinth.NewModrinthV2Client(modrinth.WithBaseURL(server.URL))
+
+	previous := []modrinth.ProjectVersion{
+		versions[4],
+		{ID: "b1", ProjectID: "BBBB", Name: "Mod B 2.0 for Fabric", VersionNumber: "2.0"},
+	}
+	next := []modrinth.ProjectVersion{
+		versions[0],
+		{ID: "c1", ProjectID: "CCCC", Name: "First beta", VersionNumber: "0.1"},
+	}
+	report, err := changelog.CompareRevisions(context.Background(), client, previous, next, changelog.Options{Loaders: []string{"fabric"}, VersionTypes: []string{"release"}})
+	if err != nil {
+		t.Fatalf("CompareRevisions() error = %v", err)
+	}
+	if len(report.Added) != 1 || len(report.Removed) != 1 || len(report.Updated) != 1 {
+		t.Fatalf("CompareRevisions() = %+v, want one added, removed and updated mod", report)
+	}
+
+	expected := `# Added
+
+- Mod C 0.1
+
+# Removed
+
+- Mod B 2.0 for Fabric 2.0
+
+# Updated
+
+## Mod A 1.1.0 → 1.3.0
+
+### 1.2.0 (2024-02-01)
+
+_No changelog provided._
+
+### 1.3.0 (2024-04-01)
+
+Added widgets
+`
+	if got := report.Markdown(); got != expected {
+		t.Errorf("Markdown() = %q, want %q", got, expected)
+	}
+}
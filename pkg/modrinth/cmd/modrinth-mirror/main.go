@@ -0,0 +1,95 @@
+// Command modrinth-mirror keeps an offline copy of Modrinth projects and serves it.
+//
+//	modrinth-mirror sync -dir ./mirror -loaders fabric -game-versions 1.20.1 sodium lithium
+//	modrinth-mirror serve -dir ./mirror -addr :8080
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth/mirror"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "sync":
+		runSync(os.Args[2:])
+	case "serve":
+		runServe(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: modrinth-mirror sync|serve [flags] [projects...]")
+	os.Exit(2)
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func runSync(args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	dir := fs.String("dir", "mirror", "mirror directory")
+	baseURL := fs.String("base-url", "https://api.modrinth.com", "upstream Modrinth API")
+	loaders := fs.String("loaders", "", "comma separated loaders to mirror")
+	gameVersions := fs.String("game-versions", "", "comma separated game versions to mirror")
+	optional := fs.Bool("optional", false, "also mirror optional dependencies")
+	concurrency := fs.Int("concurrency", 4, "parallel downloads")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("no projects given")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := modrinth.NewModrinthV2Client(modrinth.WithBaseURL(*baseURL))
+	syncer := mirror.NewSyncer(client, *dir, mirror.SyncOptions{
+		Loaders:         splitList(*loaders),
+		GameVersions:    splitList(*gameVersions),
+		IncludeOptional: *optional,
+		Concurrency:     *concurrency,
+	})
+	report, err := syncer.Sync(ctx, fs.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("synced %d projects (%d unchanged), fetched %d versions, downloaded %d files (%d bytes)",
+		report.Projects, report.UnchangedProjects, report.FetchedVersions, report.DownloadedFiles, report.DownloadedBytes)
+	for _, id := range report.SkippedProjects {
+		log.Printf("project not found: %s", id)
+	}
+}
+
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	dir := fs.String("dir", "mirror", "mirror directory")
+	addr := fs.String("addr", ":8080", "listen address")
+	publicURL := fs.String("public-url", "", "base URL used in file links")
+	fs.Parse(args)
+
+	server, err := mirror.NewServer(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	server.PublicURL = *publicURL
+	log.Printf("serving %s on %s", *dir, *addr)
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
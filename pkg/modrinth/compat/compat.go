@@ -0,0 +1,210 @@
+// Package compat computes which game versions and loaders a list of projects
+// supports, to help choose the game version of a modpack.
+package compat
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth/mcversion"
+)
+
+// Options controls how the matrix is computed.
+type Options struct {
+	// Policy decides which version types count as available. Defaults to ReleaseOnly.
+	Policy modrinth.VersionPolicy
+	// Loaders limits the matrix to these loaders. Empty keeps every loader of any project.
+	Loaders []string
+	// GameVersionTypes limits the matrix to game versions of these types, such
+	// as release or snapshot. Empty keeps all.
+	GameVersionTypes []string
+	// Optional lists the IDs or slugs of projects that are shown in the matrix
+	// but not required for a combination to be supported.
+	Optional []string
+	// Concurrency is the number of projects whose versions are fetched at once. Defaults to 4.
+	Concurrency int
+}
+
+// Key is a combination of a game version and a loader.
+type Key struct {
+	GameVersion string
+	Loader      string
+}
+
+// Matrix maps every combination of game version and loader to the newest
+// available version of each project.
+type Matrix struct {
+	// Projects are in the order they were requested.
+	Projects []modrinth.Project
+	// GameVersions are ordered from newest to oldest.
+	GameVersions []string
+	// Loaders are sorted by name.
+	Loaders []string
+	// Cells maps a combination to the available version of each project, by project ID.
+	Cells    map[Key]map[string]*modrinth.ProjectVersion
+	required map[string]bool
+	ordering *mcversion.Ordering
+}
+
+// Build fetches the projects, their versions and the game version tags, and computes the matrix.
+func Build(ctx context.Context, client *modrinth.ModrinthV2Client, idsOrSlugs []string, options Options) (*Matrix, error) {
+	ordering, err := mcversion.FetchOrdering(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := client.GetProjectsBatch(ctx, idsOrSlugs, modrinth.BatchOptions{})
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]modrinth.Project, len(projects)*2)
+	for _, p := range projects {
+		byKey[p.ID] = p
+		byKey[p.Slug] = p
+	}
+	ordered := make([]modrinth.Project, 0, len(projects))
+	seen := map[string]bool{}
+	for _, key := range idsOrSlugs {
+		p, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("project %s not found", key)
+		}
+		if !seen[p.ID] {
+			seen[p.ID] = true
+			ordered = append(ordered, p)
+		}
+	}
+
+	versions := make(map[string][]modrinth.ProjectVersion, len(ordered))
+	var mu sync.Mutex
+	var firstErr error
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, p := range ordered {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			vs, err := client.GetProjectVersions(ctx, p.ID, modrinth.GetProjectVersionsOptions{IncludeChangelog: new(bool)})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("versions of %s: %w", p.ID, err)
+				}
+				return
+			}
+			versions[p.ID] = vs
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return Compute(ordered, versions, ordering, options), nil
+}
+
+// Compute computes the matrix from already fetched projects and their versions,
+// keyed by project ID. A nil ordering orders game versions offline.
+func Compute(projects []modrinth.Project, versions map[string][]modrinth.ProjectVersion, ordering *mcversion.Ordering, options Options) *Matrix {
+	m := &Matrix{
+		Projects: projects,
+		Cells:    map[Key]map[string]*modrinth.ProjectVersion{},
+		required: map[string]bool{},
+		ordering: ordering,
+	}
+	gameVersions := map[string]bool{}
+	loaders := map[string]bool{}
+	for _, p := range projects {
+		m.required[p.ID] = !slices.Contains(options.Optional, p.ID) && !slices.Contains(options.Optional, p.Slug)
+		vs := versions[p.ID]
+		for i := range vs {
+			v := &vs[i]
+			if !options.Policy.Accepts(v.VersionType) {
+				continue
+			}
+			for _, loader := range v.Loaders {
+				if len(options.Loaders) > 0 && !slices.Contains(options.Loaders, loader) {
+					continue
+				}
+				for _, gv := range v.GameVersions {
+					if len(options.GameVersionTypes) > 0 && !slices.Contains(options.GameVersionTypes, ordering.VersionType(gv)) {
+						continue
+					}
+					gameVersions[gv] = true
+					loaders[loader] = true
+					key := Key{GameVersion: gv, Loader: loader}
+					cell := m.Cells[key]
+					if cell == nil {
+						cell = map[string]*modrinth.ProjectVersion{}
+						m.Cells[key] = cell
+					}
+					if current := cell[p.ID]; current == nil || newer(v, current) {
+						cell[p.ID] = v
+					}
+				}
+			}
+		}
+	}
+	for gv := range gameVersions {
+		m.GameVersions = append(m.GameVersions, gv)
+	}
+	ordering.Sort(m.GameVersions)
+	slices.Reverse(m.GameVersions)
+	for loader := range loaders {
+		m.Loaders = append(m.Loaders, loader)
+	}
+	slices.Sort(m.Loaders)
+	return m
+}
+
+// newer reports whether a was published after b. Versions without a valid
+// date keep the API order, which lists the newest versions first.
+func newer(a, b *modrinth.ProjectVersion) bool {
+	ta, errA := a.Published()
+	tb, errB := b.Published()
+	return errA == nil && errB == nil && ta.After(tb)
+}
+
+// Version returns the newest available version of the project for the
+// combination, or nil if there is none.
+func (m *Matrix) Version(projectID, gameVersion, loader string) *modrinth.ProjectVersion {
+	return m.Cells[Key{GameVersion: gameVersion, Loader: loader}][projectID]
+}
+
+// Missing returns the IDs of the required projects without a version for the combination.
+func (m *Matrix) Missing(key Key) []string {
+	var missing []string
+	for _, p := range m.Projects {
+		if m.required[p.ID] && m.Cells[key][p.ID] == nil {
+			missing = append(missing, p.ID)
+		}
+	}
+	return missing
+}
+
+// Supported returns the combinations where every required project has a
+// version, from the newest game version to the oldest, and by loader name.
+func (m *Matrix) Supported() []Key {
+	var keys []Key
+	for key := range m.Cells {
+		if len(m.Missing(key)) == 0 {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b Key) int {
+		if c := m.ordering.Compare(b.GameVersion, a.GameVersion); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Loader, b.Loader)
+	})
+	return keys
+}
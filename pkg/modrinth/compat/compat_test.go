@@ -0,0 +1,116 @@
+package compat_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth/compat"
+)
+
+var versions = map[string][]modrinth.ProjectVersion{
+	"AAAA": {
+		{ID: "a3", VersionType: "release", Loaders: []string{"fabric"}, GameVersions: []string{"1.21", "1.20.4"}, DatePublished: "2024-06-01T00:00:00Z"},
+		{ID: "a2", VersionType: "release", Loaders: []string{"fabric", "quilt"}, GameVersions: []string{"1.20.4", "1.20.1"}, DatePublished: "2024-02-01T00:00:00Z"},
+		{ID: "a1", VersionType: "release", Loaders: []string{"forge"}, GameVersions: []string{"1.20.1"}, DatePublished: "2023-07-01T00:00:00Z"},
+	},
+	"BBBB": {
+		{ID: "b2", VersionType: "beta", Loaders: []string{"fabric"}, GameVersions: []string{"1.21"}, DatePublished: "2024-06-15T00:00:00Z"},
+		{ID: "b1", VersionType: "release", Loaders: []string{"fabric", "forge"}, GameVersions: []string{"1.20.1", "24w14a"}, DatePublished: "2023-08-01T00:00:00Z"},
+	},
+	"CCCC": {
+		{ID: "c1", VersionType: "release", Loaders: []string{"fabric"}, GameVersions: []string{"1.20.4"}, DatePublished: "2024-01-01T00:00:00Z"},
+	},
+}
+
+func setup(t *testing.T) *modrinth.ModrinthV2Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v2/tag/game_version", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]modrinth.GameVersion{
+			{Version: "1.21", VersionType: "release", Date: "2024-06-13T00:00:00Z"},
+			{Version: "24w14a", VersionType: "snapshot", Date: "2024-04-03T00:00:00Z"},
+			{Version: "1.20.4", VersionType: "release", Date: "2023-12-07T00:00:00Z"},
+			{Version: "1.20.1", VersionType: "release", Date: "2023-06-12T00:00:00Z"},
+		})
+	})
+	mux.HandleFunc("GET /v2/projects", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]modrinth.Project{
+			{ID: "AAAA", Slug: "mod-a"},
+			{ID: "BBBB", Slug: "mod-b"},
+			{ID: "CCCC", Slug: "mod-c"},
+		})
+	})
+	mux.HandleFunc("GET /v2/project/{id}/version", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(versions[r.PathValue("id")])
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return modrinth.NewModrinthV2Client(modrinth.WithBaseURL(server.URL))
+}
+
+func TestBuild(t *testing.T) {
+	client := setup(t)
+	tests := []struct {
+		name         string
+		options      compat.Options
+		gameVersions []string
+		supported    []compat.Key
+	}{
+		{
+			name:         "Release only",
+			options:      compat.Options{Optional: []string{"mod-c"}},
+			gameVersions: []string{"1.21", "24w14a", "1.20.4", "1.20.1"},
+			supported:    []compat.Key{{GameVersion: "1.20.1", Loader: "fabric"}, {GameVersion: "1.20.1", Loader: "forge"}},
+		},
+		{
+			name:         "Beta and release game versions",
+			options:      compat.Options{Policy: modrinth.AllowBeta, GameVersionTypes: []string{"release"}, Optional: []string{"CCCC"}},
+			gameVersions: []string{"1.21", "1.20.4", "1.20.1"},
+			supported:    []compat.Key{{GameVersion: "1.21", Loader: "fabric"}, {GameVersion: "1.20.1", Loader: "fabric"}, {GameVersion: "1.20.1", Loader: "forge"}},
+		},
+		{
+			name:         "Every project required",
+			options:      compat.Options{Policy: modrinth.AllowBeta},
+			gameVersions: []string{"1.21", "24w14a", "1.20.4", "1.20.1"},
+			supported:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := compat.Build(context.Background(), client, []string{"mod-a", "BBBB", "mod-c"}, tt.options)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if !reflect.DeepEqual(m.GameVersions, tt.gameVersions) {
+				t.Errorf("GameVersions = %v, want %v", m.GameVersions, tt.gameVersions)
+			}
+			if got := m.Supported(); !reflect.DeepEqual(got, tt.supported) {
+				t.Errorf("Supported() = %v, want %v", got, tt.supported)
+			}
+		})
+	}
+}
+
+func TestMatrixVersion(t *testing.T) {
+	m, err := compat.Build(context.Background(), setup(t), []string{"AAAA", "BBBB"}, compat.Options{})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if v := m.Version("AAAA", "1.20.4", "fabric"); v == nil || v.ID != "a3" {
+		t.Errorf("Version(AAAA, 1.20.4, fabric) = %v, want a3", v)
+	}
+	if v := m.Version("AAAA", "1.20.1", "quilt"); v == nil || v.ID != "a2" {
+		t.Errorf("Version(AAAA, 1.20.1, quilt) = %v, want a2", v)
+	}
+	if missing := m.Missing(compat.Key{GameVersion: "1.20.4", Loader: "fabric"}); !reflect.DeepEqual(missing, []string{"BBBB"}) {
+		t.Errorf("Missing() = %v, want [BBBB]", missing)
+	}
+	if !reflect.DeepEqual(m.Loaders, []string{"fabric", "forge", "quilt"}) {
+		t.Errorf("Loaders = %v", m.Loaders)
+	}
+}
@@ -0,0 +1,304 @@
+// Package compliance builds license and attribution reports for the files of
+// a modpack, and flags licenses that may not allow the pack to be published.
+package compliance
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+// Status classifies a license for redistribution.
+type Status string
+
+const (
+	// Redistributable licenses are known open licenses.
+	Redistributable Status = "redistributable"
+	// NonRedistributable licenses forbid redistribution, such as All Rights Reserved.
+	NonRedistributable Status = "non-redistributable"
+	// Custom licenses are not SPDX licenses and must be reviewed by hand.
+	Custom Status = "custom"
+	// Unknown licenses are missing or not in the Modrinth license tags.
+	Unknown Status = "unknown"
+)
+
+// Options controls how licenses are classified.
+type Options struct {
+	// Permitted lists the IDs or slugs of projects whose authors allowed
+	// redistribution. Their licenses are reported but not flagged.
+	Permitted []string
+	// Algorithm is the hash algorithm of the file hashes. Defaults to sha1.
+	Algorithm string
+	// Concurrency is the number of team member requests made at once. Defaults to 4.
+	Concurrency int
+}
+
+// Author is a member of a project team.
+type Author struct {
+	Username string `json:"username"`
+	Name     string `json:"name,omitempty"`
+	Role     string `json:"role"`
+	URL      string `json:"url"`
+}
+
+// Entry describes the license and authors of one file.
+type Entry struct {
+	Hash      string                  `json:"hash"`
+	Project   modrinth.Project        `json:"-"`
+	Version   modrinth.ProjectVersion `json:"-"`
+	File      modrinth.VersionFile    `json:"-"`
+	ProjectID string                  `json:"project_id"`
+	Title     string                  `json:"title"`
+	VersionID string                  `json:"version_id"`
+	Number    string                  `json:"version_number"`
+	Filename  string                  `json:"filename"`
+	License   modrinth.License        `json:"license"`
+	Status    Status                  `json:"status"`
+	Permitted bool                    `json:"permitted,omitempty"`
+	Authors   []Author                `json:"authors"`
+}
+
+// Flagged reports whether the entry needs attention before the pack is published.
+func (e *Entry) Flagged() bool {
+	return e.Status != Redistributable && !e.Permitted
+}
+
+// Report is the license report of a set of files.
+type Report struct {
+	Generated time.Time `json:"generated"`
+	// Entries are sorted by project title.
+	Entries []Entry `json:"entries"`
+	// Unidentified lists the hashes of files Modrinth does not know.
+	Unidentified []string `json:"unidentified"`
+}
+
+// Flagged returns the entries that need attention before the pack is published.
+func (r *Report) Flagged() []Entry {
+	var flagged []Entry
+	for _, e := range r.Entries {
+		if e.Flagged() {
+			flagged = append(flagged, e)
+		}
+	}
+	return flagged
+}
+
+// OK reports whether every file is identified and none is flagged.
+func (r *Report) OK() bool {
+	return len(r.Unidentified) == 0 && len(r.Flagged()) == 0
+}
+
+// Classify classifies an SPDX license expression using the known license IDs.
+// Expressions joined by OR are redistributable if any choice is, and
+// expressions joined by AND only if all parts are.
+func Classify(expression string, known map[string]bool) Status {
+	return classify(expression, known, 0)
+}
+
+func classify(expression string, known map[string]bool, depth int) Status {
+	expression = strings.TrimSpace(expression)
+	if expression == "" || depth > 8 {
+		return Unknown
+	}
+	var alternatives []Status
+	for _, choice := range splitExpression(expression, "OR") {
+		status := Redistributable
+		for _, part := range splitExpression(choice, "AND") {
+			if s := classifyID(part, known, depth); rank(s) > rank(status) {
+				status = s
+			}
+		}
+		alternatives = append(alternatives, status)
+	}
+	return slices.MinFunc(alternatives, func(a, b Status) int { return cmp.Compare(rank(a), rank(b)) })
+}
+
+func rank(s Status) int {
+	switch s {
+	case Redistributable:
+		return 0
+	case Custom:
+		return 1
+	case Unknown:
+		return 2
+	}
+	return 3
+}
+
+// splitExpression splits an SPDX expression on a top-level operator.
+func splitExpression(expression, operator string) []string {
+	var parts []string
+	depth, start := 0, 0
+	fields := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression))
+	for i, f := range fields {
+		switch {
+		case f == "(":
+			depth++
+		case f == ")":
+			depth--
+		case depth == 0 && strings.EqualFold(f, operator):
+			parts = append(parts, strings.Join(fields[start:i], " "))
+			start = i + 1
+		}
+	}
+	parts = append(parts, strings.Join(fields[start:], " "))
+	for i, p := range parts {
+		if strings.HasPrefix(p, "( ") && strings.HasSuffix(p, " )") {
+			parts[i] = strings.TrimSpace(p[1 : len(p)-1])
+		}
+	}
+	return parts
+}
+
+func classifyID(id string, known map[string]bool, depth int) Status {
+	// An exception such as "GPL-3.0-only WITH Classpath-exception-2.0" is classified by its license.
+	id, _, _ = strings.Cut(id, " WITH ")
+	if strings.ContainsAny(id, " ()") {
+		return classify(id, known, depth+1)
+	}
+	id = strings.TrimSuffix(id, "+")
+	switch strings.ToLower(id) {
+	case "", "licenseref-unknown":
+		return Unknown
+	case "arr", "all-rights-reserved", "licenseref-all-rights-reserved":
+		return NonRedistributable
+	}
+	if strings.HasPrefix(strings.ToLower(id), "licenseref-") {
+		return Custom
+	}
+	if known[id] {
+		return Redistributable
+	}
+	return Unknown
+}
+
+// Generate identifies the files by their hashes and builds their license report.
+func Generate(ctx context.Context, client *modrinth.ModrinthV2Client, hashes []string, options Options) (*Report, error) {
+	algorithm := options.Algorithm
+	if algorithm == "" {
+		algorithm = "sha1"
+	}
+	versions, err := client.GetProjectVersionsByHashBatch(ctx, hashes, algorithm, modrinth.BatchOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var projectIDs []string
+	for _, v := range versions {
+		projectIDs = append(projectIDs, v.ProjectID)
+	}
+	projects, err := client.GetProjectsBatch(ctx, projectIDs, modrinth.BatchOptions{})
+	if err != nil {
+		return nil, err
+	}
+	tags, err := client.GetLicenseTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	members, err := fetchMembers(ctx, client, projects, options.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+	return Build(hashes, algorithm, versions, projects, tags, members, options), nil
+}
+
+func fetchMembers(ctx context.Context, client *modrinth.ModrinthV2Client, projects []modrinth.Project, concurrency int) (map[string][]modrinth.TeamMember, error) {
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	members := make(map[string][]modrinth.TeamMember, len(projects))
+	var mu sync.Mutex
+	var firstErr error
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, p := range projects {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			team, err := client.GetProjectTeamMembers(ctx, p.ID)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("team of %s: %w", p.ID, err)
+				}
+				return
+			}
+			members[p.ID] = team
+		}()
+	}
+	wg.Wait()
+	return members, firstErr
+}
+
+// Build builds the report from already fetched data: the versions by file
+// hash, the projects, the license tags and the team members by project ID.
+func Build(hashes []string, algorithm string, versions map[string]modrinth.ProjectVersion, projects []modrinth.Project, tags []modrinth.License, members map[string][]modrinth.TeamMember, options Options) *Report {
+	known := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		known[t.ID] = true
+	}
+	byID := make(map[string]modrinth.Project, len(projects))
+	for _, p := range projects {
+		byID[p.ID] = p
+	}
+
+	r := &Report{Generated: time.Now().UTC()}
+	seen := map[string]bool{}
+	for _, hash := range hashes {
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		v, ok := versions[hash]
+		if !ok {
+			r.Unidentified = append(r.Unidentified, hash)
+			continue
+		}
+		p := byID[v.ProjectID]
+		e := Entry{
+			Hash:      hash,
+			Project:   p,
+			Version:   v,
+			ProjectID: v.ProjectID,
+			Title:     cmp.Or(p.Title, p.Slug, v.ProjectID),
+			VersionID: v.ID,
+			Number:    v.VersionNumber,
+			License:   p.License,
+			Status:    Classify(p.License.ID, known),
+			Permitted: slices.Contains(options.Permitted, p.ID) || (p.Slug != "" && slices.Contains(options.Permitted, p.Slug)),
+			Authors:   []Author{},
+		}
+		for _, f := range v.Files {
+			if f.Hashes[algorithm] == hash {
+				e.File = f
+				e.Filename = f.Filename
+			}
+		}
+		team := slices.Clone(members[p.ID])
+		slices.SortStableFunc(team, func(a, b modrinth.TeamMember) int { return cmp.Compare(a.Ordering, b.Ordering) })
+		for _, m := range team {
+			if !m.Accepted {
+				continue
+			}
+			e.Authors = append(e.Authors, Author{
+				Username: m.User.Username,
+				Name:     m.User.Name,
+				Role:     m.Role,
+				URL:      "https://modrinth.com/user/" + m.User.Username,
+			})
+		}
+		r.Entries = append(r.Entries, e)
+	}
+	slices.SortStableFunc(r.Entries, func(a, b Entry) int {
+		return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	})
+	return r
+}
@@ -0,0 +1,135 @@
+package compliance_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth/compliance"
+)
+
+func TestClassify(t *testing.T) {
+	known := map[string]bool{"MIT": true, "GPL-3.0-only": true, "LGPL-3.0-only": true, "Apache-2.0": true}
+	tests := []struct {
+		name       string
+		expression string
+		expected   compliance.Status
+	}{
+		{name: "SPDX", expression: "MIT", expected: compliance.Redistributable},
+		{name: "Empty", expression: "", expected: compliance.Unknown},
+		{name: "All rights reserved", expression: "LicenseRef-All-Rights-Reserved", expected: compliance.NonRedistributable},
+		{name: "Custom", expression: "LicenseRef-Custom", expected: compliance.Custom},
+		{name: "Not a tag", expression: "WTFPL-2", expected: compliance.Unknown},
+		{name: "OR", expression: "LicenseRef-All-Rights-Reserved OR MIT", expected: compliance.Redistributable},
+		{name: "AND", expression: "MIT AND LicenseRef-Custom", expected: compliance.Custom},
+		{name: "Parentheses", expression: "(MIT OR Apache-2.0) AND LicenseRef-All-Rights-Reserved", expected: compliance.NonRedistributable},
+		{name: "WITH", expression: "GPL-3.0-only WITH Classpath-exception-2.0", expected: compliance.Redistributable},
+		{name: "Malformed", expression: "(MIT) (", expected: compliance.Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compliance.Classify(tt.expression, known); got != tt.expected {
+				t.Errorf("Classify(%q) = %v, want %v", tt.expression, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v2/version_files", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]modrinth.ProjectVersion{
+			"aaa": {ID: "va", ProjectID: "AAAA", VersionNumber: "1.0", Files: []modrinth.VersionFile{{Filename: "a.jar", URL: "https://cdn.modrinth.com/a.jar", Hashes: map[string]string{"sha1": "aaa", "sha512": "aaa512"}}}},
+			"bbb": {ID: "vb", ProjectID: "BBBB", VersionNumber: "2.0", Files: []modrinth.VersionFile{{Filename: "b.jar", Hashes: map[string]string{"sha1": "bbb"}}}},
+		})
+	})
+	mux.HandleFunc("GET /v2/projects", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id": "AAAA", "slug": "mod-a", "title": "Mod A", "license": {"id": "MIT", "name": "MIT License", "url": "https://opensource.org/licenses/MIT"}},
+			{"id": "BBBB", "slug": "mod-b", "title": "Mod B", "license": {"id": "LicenseRef-All-Rights-Reserved", "name": "All Rights Reserved"}}
+		]`))
+	})
+	mux.HandleFunc("GET /v2/tag/license", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"short": "MIT", "name": "MIT License"}]`))
+	})
+	mux.HandleFunc("GET /v2/project/{id}/members", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]modrinth.TeamMember{
+			{User: modrinth.User{Username: "helper"}, Role: "Contributor", Accepted: true, Ordering: 1},
+			{User: modrinth.User{Username: "author-" + strings.ToLower(r.PathValue("id")[:1])}, Role: "Owner", Accepted: true},
+			{User: modrinth.User{Username: "invited"}, Role: "Member", Ordering: 2},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := modrinth.NewModrinthV2Client(modrinth.WithBaseURL(server.URL))
+
+	report, err := compliance.Generate(context.Background(), client, []string{"bbb", "aaa", "ccc"}, compliance.Options{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(report.Entries) != 2 || report.Entries[0].Title != "Mod A" || report.Entries[0].Filename != "a.jar" {
+		t.Fatalf("Entries = %+v, want Mod A then Mod B", report.Entries)
+	}
+	if got := report.Entries[0].Authors; len(got) != 2 || got[0].Username != "author-a" || got[1].Username != "helper" {
+		t.Errorf("Authors = %+v, want accepted members in team order", got)
+	}
+	if flagged := report.Flagged(); len(flagged) != 1 || flagged[0].ProjectID != "BBBB" || flagged[0].Status != compliance.NonRedistributable {
+		t.Errorf("Flagged() = %+v, want Mod B", flagged)
+	}
+	if len(report.Unidentified) != 1 || report.Unidentified[0] != "ccc" || report.OK() {
+		t.Errorf("Unidentified = %v, OK() = %v", report.Unidentified, report.OK())
+	}
+
+	markdown := report.Markdown()
+	for _, want := range []string{
+		"| [Mod A](https://modrinth.com/project/AAAA) | 1.0 | [MIT](https://opensource.org/licenses/MIT) | redistributable |",
+		"- Mod B: non-redistributable license LicenseRef-All-Rights-Reserved",
+		"- Unidentified file ccc",
+		"- **Mod A** by [author-a](https://modrinth.com/user/author-a), [helper](https://modrinth.com/user/helper)",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown() does not contain %q:\n%s", want, markdown)
+		}
+	}
+
+	data, err := report.CycloneDX()
+	if err != nil {
+		t.Fatalf("CycloneDX() error = %v", err)
+	}
+	var bom struct {
+		BOMFormat  string `json:"bomFormat"`
+		Components []struct {
+			BOMRef   string `json:"bom-ref"`
+			Hashes   []struct{ Alg, Content string }
+			Licenses []struct {
+				License struct{ ID, Name string }
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		t.Fatalf("CycloneDX() is not JSON: %v", err)
+	}
+	if bom.BOMFormat != "CycloneDX" || len(bom.Components) != 2 {
+		t.Fatalf("CycloneDX() = %s", data)
+	}
+	a, b := bom.Components[0], bom.Components[1]
+	if a.BOMRef != "va" || a.Licenses[0].License.ID != "MIT" || len(a.Hashes) != 2 || a.Hashes[0].Alg != "SHA-1" {
+		t.Errorf("component a = %+v", a)
+	}
+	if b.Licenses[0].License.Name != "LicenseRef-All-Rights-Reserved" {
+		t.Errorf("component b = %+v", b)
+	}
+
+	permitted, err := compliance.Generate(context.Background(), client, []string{"aaa", "bbb"}, compliance.Options{Permitted: []string{"mod-b"}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !permitted.OK() {
+		t.Errorf("OK() = false with permission for mod-b, flagged %+v", permitted.Flagged())
+	}
+}
@@ -0,0 +1,163 @@
+package compliance
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Markdown renders the report as a Markdown document with a license table,
+// the flagged files and the credits of every project.
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("# Licenses\n\n")
+	sb.WriteString("| Project | Version | License | Status |\n|---|---|---|---|\n")
+	for _, e := range r.Entries {
+		status := string(e.Status)
+		if e.Permitted && e.Status != Redistributable {
+			status += " (permitted)"
+		}
+		fmt.Fprintf(&sb, "| [%s](https://modrinth.com/project/%s) | %s | %s | %s |\n",
+			escapeCell(e.Title), e.ProjectID, escapeCell(e.Number), escapeCell(licenseLabel(e)), status)
+	}
+
+	flagged := r.Flagged()
+	if len(flagged) > 0 || len(r.Unidentified) > 0 {
+		sb.WriteString("\n## Needs Review\n\n")
+		for _, e := range flagged {
+			fmt.Fprintf(&sb, "- %s: %s license %s\n", e.Title, e.Status, licenseLabel(e))
+		}
+		for _, hash := range r.Unidentified {
+			fmt.Fprintf(&sb, "- Unidentified file %s\n", hash)
+		}
+	}
+
+	sb.WriteString("\n## Credits\n\n")
+	for _, e := range r.Entries {
+		var authors []string
+		for _, a := range e.Authors {
+			authors = append(authors, fmt.Sprintf("[%s](%s)", a.Username, a.URL))
+		}
+		if len(authors) == 0 {
+			authors = []string{"unknown"}
+		}
+		fmt.Fprintf(&sb, "- **%s** by %s\n", e.Title, strings.Join(authors, ", "))
+	}
+	return sb.String()
+}
+
+func licenseLabel(e Entry) string {
+	switch {
+	case e.License.ID == "":
+		return "none"
+	case e.License.URL != "":
+		return fmt.Sprintf("[%s](%s)", e.License.ID, e.License.URL)
+	}
+	return e.License.ID
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// JSON encodes the report as indented JSON.
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+type cycloneDX struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    cycloneDXMetadata    `json:"metadata"`
+	Components  []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string `json:"timestamp"`
+}
+
+type cycloneDXComponent struct {
+	Type               string              `json:"type"`
+	BOMRef             string              `json:"bom-ref"`
+	Author             string              `json:"author,omitempty"`
+	Name               string              `json:"name"`
+	Version            string              `json:"version"`
+	Hashes             []cycloneDXHash     `json:"hashes,omitempty"`
+	Licenses           []cycloneDXLicense  `json:"licenses,omitempty"`
+	ExternalReferences []cycloneDXExternal `json:"externalReferences,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type cycloneDXLicense struct {
+	License    *cycloneDXLicenseID `json:"license,omitempty"`
+	Expression string              `json:"expression,omitempty"`
+}
+
+type cycloneDXLicenseID struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type cycloneDXExternal struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+var cycloneDXAlgorithms = map[string]string{"sha1": "SHA-1", "sha512": "SHA-512"}
+
+// CycloneDX encodes the report as a CycloneDX 1.5 software bill of materials
+// in JSON, with one library component per file.
+func (r *Report) CycloneDX() ([]byte, error) {
+	bom := cycloneDX{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata:    cycloneDXMetadata{Timestamp: r.Generated.Format(time.RFC3339)},
+		Components:  []cycloneDXComponent{},
+	}
+	for _, e := range r.Entries {
+		c := cycloneDXComponent{
+			Type:    "library",
+			BOMRef:  e.VersionID,
+			Name:    e.Title,
+			Version: e.Number,
+			ExternalReferences: []cycloneDXExternal{
+				{Type: "website", URL: "https://modrinth.com/project/" + e.ProjectID},
+			},
+		}
+		var authors []string
+		for _, a := range e.Authors {
+			authors = append(authors, a.Username)
+		}
+		c.Author = strings.Join(authors, ", ")
+		for algorithm, hash := range e.File.Hashes {
+			if name, ok := cycloneDXAlgorithms[algorithm]; ok {
+				c.Hashes = append(c.Hashes, cycloneDXHash{Algorithm: name, Content: hash})
+			}
+		}
+		slices.SortFunc(c.Hashes, func(a, b cycloneDXHash) int { return cmp.Compare(a.Algorithm, b.Algorithm) })
+		switch id := e.License.ID; {
+		case id == "":
+		case strings.ContainsAny(id, " ()"):
+			c.Licenses = []cycloneDXLicense{{Expression: id}}
+		case strings.HasPrefix(strings.ToLower(id), "licenseref-"):
+			c.Licenses = []cycloneDXLicense{{License: &cycloneDXLicenseID{Name: id, URL: e.License.URL}}}
+		default:
+			c.Licenses = []cycloneDXLicense{{License: &cycloneDXLicenseID{ID: id, URL: e.License.URL}}}
+		}
+		if e.File.URL != "" {
+			c.ExternalReferences = append(c.ExternalReferences, cycloneDXExternal{Type: "distribution", URL: e.File.URL})
+		}
+		bom.Components = append(bom.Components, c)
+	}
+	return json.MarshalIndent(bom, "", "  ")
+}
@@ -0,0 +1,414 @@
+package modrinth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Every model keeps the JSON fields it does not declare in its Extra map, and
+// writes them back when encoded, so re-serializing a response does not lose
+// fields Modrinth added after this package was written.
+
+// UnknownFieldsError is returned in strict decoding mode when a response has
+// fields the models do not declare.
+type UnknownFieldsError struct {
+	// Fields are the paths of the unknown fields, such as "ProjectVersion.files[0].sha3".
+	Fields []string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return "unknown fields in response: " + strings.Join(e.Fields, ", ")
+}
+
+var knownFieldsCache sync.Map // reflect.Type -> map[string]bool
+
+// knownFields returns the lower-cased JSON names of the fields of a struct type.
+// encoding/json matches names case-insensitively, so unknown fields must too.
+func knownFields(t reflect.Type) map[string]bool {
+	if known, ok := knownFieldsCache.Load(t); ok {
+		return known.(map[string]bool)
+	}
+	known := map[string]bool{}
+	for i := range t.NumField() {
+		if name := jsonName(t.Field(i)); name != "" {
+			known[strings.ToLower(name)] = true
+		}
+	}
+	knownFieldsCache.Store(t, known)
+	return known
+}
+
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
+
+// unknownFields returns the members of a JSON object that are not known, or nil if there are none.
+func unknownFields(data []byte, known map[string]bool) (map[string]json.RawMessage, error) {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil, nil
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	for key := range members {
+		if known[strings.ToLower(key)] {
+			delete(members, key)
+		}
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members, nil
+}
+
+// decodeExtra decodes data into v, a pointer to a struct type without
+// methods, and stores the unknown fields in extra.
+func decodeExtra(data []byte, v any, extra *map[string]json.RawMessage) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	members, err := unknownFields(data, knownFields(reflect.TypeOf(v).Elem()))
+	if err != nil {
+		return err
+	}
+	*extra = members
+	return nil
+}
+
+// encodeExtra encodes v, a struct without methods, and appends the extra
+// fields that do not collide with declared ones, sorted by name.
+func encodeExtra(v any, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+	known := knownFields(reflect.TypeOf(v))
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		if !known[strings.ToLower(key)] {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	for _, key := range keys {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(extra[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// CheckUnknownFields walks a decoded value and returns an *UnknownFieldsError
+// listing every field kept in an Extra map, or nil if there are none. Tests
+// can use it to notice API drift.
+func CheckUnknownFields(v any) error {
+	var fields []string
+	walkUnknown(reflect.ValueOf(v), "", &fields)
+	if len(fields) == 0 {
+		return nil
+	}
+	slices.Sort(fields)
+	return &UnknownFieldsError{Fields: fields}
+}
+
+var rawMessageMapType = reflect.TypeOf(map[string]json.RawMessage(nil))
+
+func walkUnknown(v reflect.Value, path string, fields *[]string) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			walkUnknown(v.Elem(), path, fields)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			walkUnknown(v.Index(i), fmt.Sprintf("%s[%d]", path, i), fields)
+		}
+	case reflect.Map:
+		if v.Type() == rawMessageMapType {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			walkUnknown(v.MapIndex(key), fmt.Sprintf("%s[%v]", path, key), fields)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		if path == "" {
+			path = t.Name()
+		}
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if f.Name == "Extra" && f.Type == rawMessageMapType {
+				for key := range v.Field(i).Interface().(map[string]json.RawMessage) {
+					*fields = append(*fields, path+"."+key)
+				}
+				continue
+			}
+			if name := jsonName(f); name != "" {
+				walkUnknown(v.Field(i), path+"."+name, fields)
+			}
+		}
+	}
+}
+
+// UnmarshalJSON decodes the SearchResultHit and keeps unknown fields in Extra.
+func (s *SearchResultHit) UnmarshalJSON(data []byte) error {
+	type plain SearchResultHit
+	return decodeExtra(data, (*plain)(s), &s.Extra)
+}
+
+// MarshalJSON encodes the SearchResultHit together with the fields in Extra.
+func (s SearchResultHit) MarshalJSON() ([]byte, error) {
+	type plain SearchResultHit
+	return encodeExtra(plain(s), s.Extra)
+}
+
+// UnmarshalJSON decodes the SearchResult and keeps unknown fields in Extra.
+func (s *SearchResult) UnmarshalJSON(data []byte) error {
+	type plain SearchResult
+	return decodeExtra(data, (*plain)(s), &s.Extra)
+}
+
+// MarshalJSON encodes the SearchResult together with the fields in Extra.
+func (s SearchResult) MarshalJSON() ([]byte, error) {
+	type plain SearchResult
+	return encodeExtra(plain(s), s.Extra)
+}
+
+// UnmarshalJSON decodes the Project and keeps unknown fields in Extra.
+func (p *Project) UnmarshalJSON(data []byte) error {
+	type plain Project
+	return decodeExtra(data, (*plain)(p), &p.Extra)
+}
+
+// MarshalJSON encodes the Project together with the fields in Extra.
+func (p Project) MarshalJSON() ([]byte, error) {
+	type plain Project
+	return encodeExtra(plain(p), p.Extra)
+}
+
+// UnmarshalJSON decodes the ModeratorMessage and keeps unknown fields in Extra.
+func (m *ModeratorMessage) UnmarshalJSON(data []byte) error {
+	type plain ModeratorMessage
+	return decodeExtra(data, (*plain)(m), &m.Extra)
+}
+
+// MarshalJSON encodes the ModeratorMessage together with the fields in Extra.
+func (m ModeratorMessage) MarshalJSON() ([]byte, error) {
+	type plain ModeratorMessage
+	return encodeExtra(plain(m), m.Extra)
+}
+
+// UnmarshalJSON decodes the DonationURL and keeps unknown fields in Extra.
+func (d *DonationURL) UnmarshalJSON(data []byte) error {
+	type plain DonationURL
+	return decodeExtra(data, (*plain)(d), &d.Extra)
+}
+
+// MarshalJSON encodes the DonationURL together with the fields in Extra.
+func (d DonationURL) MarshalJSON() ([]byte, error) {
+	type plain DonationURL
+	return encodeExtra(plain(d), d.Extra)
+}
+
+// UnmarshalJSON decodes the GalleryItem and keeps unknown fields in Extra.
+func (g *GalleryItem) UnmarshalJSON(data []byte) error {
+	type plain GalleryItem
+	return decodeExtra(data, (*plain)(g), &g.Extra)
+}
+
+// MarshalJSON encodes the GalleryItem together with the fields in Extra.
+func (g GalleryItem) MarshalJSON() ([]byte, error) {
+	type plain GalleryItem
+	return encodeExtra(plain(g), g.Extra)
+}
+
+// UnmarshalJSON decodes the ProjectVersion and keeps unknown fields in Extra.
+func (v *ProjectVersion) UnmarshalJSON(data []byte) error {
+	type plain ProjectVersion
+	return decodeExtra(data, (*plain)(v), &v.Extra)
+}
+
+// MarshalJSON encodes the ProjectVersion together with the fields in Extra.
+func (v ProjectVersion) MarshalJSON() ([]byte, error) {
+	type plain ProjectVersion
+	return encodeExtra(plain(v), v.Extra)
+}
+
+// UnmarshalJSON decodes the VersionFile and keeps unknown fields in Extra.
+func (v *VersionFile) UnmarshalJSON(data []byte) error {
+	type plain VersionFile
+	return decodeExtra(data, (*plain)(v), &v.Extra)
+}
+
+// MarshalJSON encodes the VersionFile together with the fields in Extra.
+func (v VersionFile) MarshalJSON() ([]byte, error) {
+	type plain VersionFile
+	return encodeExtra(plain(v), v.Extra)
+}
+
+// UnmarshalJSON decodes the VersionDependency and keeps unknown fields in Extra.
+func (v *VersionDependency) UnmarshalJSON(data []byte) error {
+	type plain VersionDependency
+	return decodeExtra(data, (*plain)(v), &v.Extra)
+}
+
+// MarshalJSON encodes the VersionDependency together with the fields in Extra.
+func (v VersionDependency) MarshalJSON() ([]byte, error) {
+	type plain VersionDependency
+	return encodeExtra(plain(v), v.Extra)
+}
+
+// UnmarshalJSON decodes the ProjectDependencies and keeps unknown fields in Extra.
+func (p *ProjectDependencies) UnmarshalJSON(data []byte) error {
+	type plain ProjectDependencies
+	return decodeExtra(data, (*plain)(p), &p.Extra)
+}
+
+// MarshalJSON encodes the ProjectDependencies together with the fields in Extra.
+func (p ProjectDependencies) MarshalJSON() ([]byte, error) {
+	type plain ProjectDependencies
+	return encodeExtra(plain(p), p.Extra)
+}
+
+// UnmarshalJSON decodes the Category and keeps unknown fields in Extra.
+func (c *Category) UnmarshalJSON(data []byte) error {
+	type plain Category
+	return decodeExtra(data, (*plain)(c), &c.Extra)
+}
+
+// MarshalJSON encodes the Category together with the fields in Extra.
+func (c Category) MarshalJSON() ([]byte, error) {
+	type plain Category
+	return encodeExtra(plain(c), c.Extra)
+}
+
+// UnmarshalJSON decodes the GameVersion and keeps unknown fields in Extra.
+func (g *GameVersion) UnmarshalJSON(data []byte) error {
+	type plain GameVersion
+	return decodeExtra(data, (*plain)(g), &g.Extra)
+}
+
+// MarshalJSON encodes the GameVersion together with the fields in Extra.
+func (g GameVersion) MarshalJSON() ([]byte, error) {
+	type plain GameVersion
+	return encodeExtra(plain(g), g.Extra)
+}
+
+// UnmarshalJSON decodes the Loader and keeps unknown fields in Extra.
+func (l *Loader) UnmarshalJSON(data []byte) error {
+	type plain Loader
+	return decodeExtra(data, (*plain)(l), &l.Extra)
+}
+
+// MarshalJSON encodes the Loader together with the fields in Extra.
+func (l Loader) MarshalJSON() ([]byte, error) {
+	type plain Loader
+	return encodeExtra(plain(l), l.Extra)
+}
+
+// UnmarshalJSON decodes the DonationPlatform and keeps unknown fields in Extra.
+func (d *DonationPlatform) UnmarshalJSON(data []byte) error {
+	type plain DonationPlatform
+	return decodeExtra(data, (*plain)(d), &d.Extra)
+}
+
+// MarshalJSON encodes the DonationPlatform together with the fields in Extra.
+func (d DonationPlatform) MarshalJSON() ([]byte, error) {
+	type plain DonationPlatform
+	return encodeExtra(plain(d), d.Extra)
+}
+
+// UnmarshalJSON decodes the Statistics and keeps unknown fields in Extra.
+func (s *Statistics) UnmarshalJSON(data []byte) error {
+	type plain Statistics
+	return decodeExtra(data, (*plain)(s), &s.Extra)
+}
+
+// MarshalJSON encodes the Statistics together with the fields in Extra.
+func (s Statistics) MarshalJSON() ([]byte, error) {
+	type plain Statistics
+	return encodeExtra(plain(s), s.Extra)
+}
+
+// UnmarshalJSON decodes the User and keeps unknown fields in Extra.
+func (u *User) UnmarshalJSON(data []byte) error {
+	type plain User
+	return decodeExtra(data, (*plain)(u), &u.Extra)
+}
+
+// MarshalJSON encodes the User together with the fields in Extra.
+func (u User) MarshalJSON() ([]byte, error) {
+	type plain User
+	return encodeExtra(plain(u), u.Extra)
+}
+
+// UnmarshalJSON decodes the TeamMember and keeps unknown fields in Extra.
+func (t *TeamMember) UnmarshalJSON(data []byte) error {
+	type plain TeamMember
+	return decodeExtra(data, (*plain)(t), &t.Extra)
+}
+
+// MarshalJSON encodes the TeamMember together with the fields in Extra.
+func (t TeamMember) MarshalJSON() ([]byte, error) {
+	type plain TeamMember
+	return encodeExtra(plain(t), t.Extra)
+}
+
+// UnmarshalJSON decodes the Collection and keeps unknown fields in Extra.
+func (c *Collection) UnmarshalJSON(data []byte) error {
+	type plain Collection
+	return decodeExtra(data, (*plain)(c), &c.Extra)
+}
+
+// MarshalJSON encodes the Collection together with the fields in Extra.
+func (c Collection) MarshalJSON() ([]byte, error) {
+	type plain Collection
+	return encodeExtra(plain(c), c.Extra)
+}
+
+// UnmarshalJSON decodes the PayoutBalance and keeps unknown fields in Extra.
+func (p *PayoutBalance) UnmarshalJSON(data []byte) error {
+	type plain PayoutBalance
+	return decodeExtra(data, (*plain)(p), &p.Extra)
+}
+
+// MarshalJSON encodes the PayoutBalance together with the fields in Extra.
+func (p PayoutBalance) MarshalJSON() ([]byte, error) {
+	type plain PayoutBalance
+	return encodeExtra(plain(p), p.Extra)
+}
+
+// UnmarshalJSON decodes the Payout and keeps unknown fields in Extra.
+func (p *Payout) UnmarshalJSON(data []byte) error {
+	type plain Payout
+	return decodeExtra(data, (*plain)(p), &p.Extra)
+}
+
+// MarshalJSON encodes the Payout together with the fields in Extra.
+func (p Payout) MarshalJSON() ([]byte, error) {
+	type plain Payout
+	return encodeExtra(plain(p), p.Extra)
+}
@@ -0,0 +1,134 @@
+package modrinth_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+func TestUnknownFieldsRoundTrip(t *testing.T) {
+	input := `{"id":"IIJJKKLL","project_id":"AABBCCDD","name":"1.0","version_number":"1.0","date_published":"2024-01-01T00:00:00Z","downloads":5,"version_type":"release","status":"listed","files":[{"hashes":{"sha1":"abc"},"url":"https://cdn.modrinth.com/a.jar","filename":"a.jar","primary":true,"size":10,"sha3":{"nested":[1,2]}}],"dependencies":[],"game_versions":["1.20.1"],"loaders":["fabric"],"featured":false,"author_id":"USER","environment":"client_only","ordering":null}`
+
+	var version modrinth.ProjectVersion
+	if err := json.Unmarshal([]byte(input), &version); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	expectedExtra := map[string]json.RawMessage{"environment": json.RawMessage(`"client_only"`), "ordering": json.RawMessage(`null`)}
+	if !reflect.DeepEqual(version.Extra, expectedExtra) {
+		t.Errorf("Extra = %s, want %s", version.Extra, expectedExtra)
+	}
+	if got := string(version.Files[0].Extra["sha3"]); got != `{"nested":[1,2]}` {
+		t.Errorf("Files[0].Extra[sha3] = %s", got)
+	}
+
+	output, err := json.Marshal(version)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var want, got map[string]any
+	json.Unmarshal([]byte(input), &want)
+	json.Unmarshal(output, &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %s, want %s", output, input)
+	}
+
+	var known modrinth.Category
+	if err := json.Unmarshal([]byte(`{"icon":"<svg/>","name":"magic","project_type":"mod","header":"categories","NAME":"ignored"}`), &known); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if known.Extra != nil {
+		t.Errorf("Extra = %v, want nil for a case-insensitive match", known.Extra)
+	}
+}
+
+func TestLicenseUnknownFields(t *testing.T) {
+	var license modrinth.License
+	if err := json.Unmarshal([]byte(`{"id":"MIT","name":"MIT License","url":"https://opensource.org/licenses/MIT","spdx":true}`), &license); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if license.ID != "MIT" || license.URL != "https://opensource.org/licenses/MIT" || string(license.Extra["spdx"]) != "true" || len(license.Extra) != 1 {
+		t.Errorf("Unmarshal() = %+v", license)
+	}
+	output, _ := json.Marshal(license)
+	if expected := `{"id":"MIT","name":"MIT License","url":"https://opensource.org/licenses/MIT","spdx":true}`; string(output) != expected {
+		t.Errorf("Marshal() = %s, want %s", output, expected)
+	}
+
+	output, _ = json.Marshal(modrinth.License{ID: "MIT", Name: "MIT License"})
+	if expected := `{"short":"MIT","name":"MIT License"}`; string(output) != expected {
+		t.Errorf("Marshal() = %s, want %s", output, expected)
+	}
+
+	// An empty name is still written, for consumers that expect the key.
+	output, _ = json.Marshal(modrinth.License{ID: "LicenseRef-Custom"})
+	if expected := `{"short":"LicenseRef-Custom","name":""}`; string(output) != expected {
+		t.Errorf("Marshal() = %s, want %s", output, expected)
+	}
+}
+
+func TestProjectLicenseRoundTrip(t *testing.T) {
+	for _, license := range []string{
+		`{"id":"LGPL-3.0-only","name":"GNU Lesser General Public License v3.0 only"}`,
+		`{"id":"LicenseRef-Custom","name":"","url":"https://example.com/LICENSE"}`,
+		`{"short":"MIT","name":"MIT","link":"https://opensource.org/licenses/MIT"}`,
+	} {
+		input := `{"id":"AABBCCDD","slug":"sodium","title":"Sodium","license":` + license + `}`
+		var project modrinth.Project
+		if err := json.Unmarshal([]byte(input), &project); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		output, err := json.Marshal(project)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		var got struct {
+			License map[string]any `json:"license"`
+		}
+		var want map[string]any
+		json.Unmarshal(output, &got)
+		json.Unmarshal([]byte(license), &want)
+		if !reflect.DeepEqual(got.License, want) {
+			t.Errorf("round trip license = %v, want %s", got.License, license)
+		}
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{name: "Known fields", body: `{"id":"AABBCCDD","slug":"sodium","license":{"id":"MIT","name":"MIT"}}`},
+		{name: "Unknown fields", body: `{"id":"AABBCCDD","new_field":1,"gallery":[{"url":"x","blurhash":"y"}]}`, expected: []string{"Project.gallery[0].blurhash", "Project.new_field"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+			client := modrinth.NewModrinthV2Client(modrinth.WithBaseURL(server.URL), modrinth.WithStrictDecoding())
+
+			_, err := client.GetProject(context.Background(), "AABBCCDD")
+			var unknown *modrinth.UnknownFieldsError
+			if tt.expected == nil {
+				if err != nil {
+					t.Errorf("GetProject() error = %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &unknown) || !reflect.DeepEqual(unknown.Fields, tt.expected) {
+				t.Errorf("GetProject() error = %v, want unknown fields %v", err, tt.expected)
+			}
+		})
+	}
+}
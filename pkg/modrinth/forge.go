@@ -0,0 +1,105 @@
+package modrinth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// ForgeUpdates is the update JSON Modrinth serves for Forge's update checker.
+type ForgeUpdates struct {
+	// Homepage is the project page on Modrinth.
+	Homepage string
+	// Promos maps "<game version>-latest" and "<game version>-recommended" to
+	// a version number.
+	Promos map[string]string
+	// Versions maps a game version to the version numbers released for it and
+	// their changelogs.
+	Versions map[string]map[string]string
+}
+
+// UnmarshalJSON decodes the update JSON. Every key besides homepage and promos
+// is a game version.
+func (f *ForgeUpdates) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*f = ForgeUpdates{}
+	for key, value := range raw {
+		var err error
+		switch key {
+		case "homepage":
+			err = json.Unmarshal(value, &f.Homepage)
+		case "promos":
+			err = json.Unmarshal(value, &f.Promos)
+		default:
+			var changelogs map[string]string
+			if err = json.Unmarshal(value, &changelogs); err == nil {
+				if f.Versions == nil {
+					f.Versions = map[string]map[string]string{}
+				}
+				f.Versions[key] = changelogs
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the update JSON in the format Forge reads.
+func (f ForgeUpdates) MarshalJSON() ([]byte, error) {
+	out := map[string]any{"homepage": f.Homepage, "promos": f.Promos}
+	if f.Promos == nil {
+		out["promos"] = map[string]string{}
+	}
+	for gameVersion, changelogs := range f.Versions {
+		out[gameVersion] = changelogs
+	}
+	return json.Marshal(out)
+}
+
+// Latest returns the latest version number for the game version.
+func (f *ForgeUpdates) Latest(gameVersion string) (string, bool) {
+	version, ok := f.Promos[gameVersion+"-latest"]
+	return version, ok
+}
+
+// Recommended returns the recommended version number for the game version,
+// which is the latest release.
+func (f *ForgeUpdates) Recommended(gameVersion string) (string, bool) {
+	version, ok := f.Promos[gameVersion+"-recommended"]
+	return version, ok
+}
+
+// ForgeUpdatesOptions represents the options for fetching the Forge update JSON.
+type ForgeUpdatesOptions struct {
+	// NeoForge is "include" to consider NeoForge versions as well as Forge
+	// ones, or "only" to consider NeoForge versions alone. Empty considers
+	// Forge versions only.
+	NeoForge string
+}
+
+// GetForgeUpdates fetches the Forge update JSON of a project by ID or slug.
+// It is the document a mod's updateJSONURL can point at.
+func (c *ModrinthV2Client) GetForgeUpdates(ctx context.Context, projectID string, options ForgeUpdatesOptions) (*ForgeUpdates, error) {
+	path := ForgeUpdatesPath(projectID, options)
+	var updates ForgeUpdates
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &updates)
+	return &updates, err
+}
+
+// ForgeUpdatesPath returns the path of a project's Forge update JSON relative
+// to the API base URL, for use in a mods.toml updateJSONURL.
+func ForgeUpdatesPath(projectID string, options ForgeUpdatesOptions) string {
+	path := "/updates/" + url.PathEscape(projectID) + "/forge_updates.json"
+	if options.NeoForge != "" {
+		v := url.Values{}
+		v.Add("neoforge", options.NeoForge)
+		path += "?" + v.Encode()
+	}
+	return path
+}
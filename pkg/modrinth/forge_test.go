@@ -0,0 +1,90 @@
+package modrinth_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+func TestGetForgeUpdates(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  modrinth.ForgeUpdatesOptions
+		query    string
+		status   int
+		body     string
+		expected *modrinth.ForgeUpdates
+		wantErr  bool
+	}{
+		{
+			name:   "Forge",
+			status: http.StatusOK,
+			body:   `{"homepage":"https://modrinth.com/mod/jei","promos":{"1.20.1-latest":"15.2.0","1.20.1-recommended":"15.1.0"},"1.20.1":{"15.2.0":"Fixes","15.1.0":""}}`,
+			expected: &modrinth.ForgeUpdates{
+				Homepage: "https://modrinth.com/mod/jei",
+				Promos:   map[string]string{"1.20.1-latest": "15.2.0", "1.20.1-recommended": "15.1.0"},
+				Versions: map[string]map[string]string{"1.20.1": {"15.2.0": "Fixes", "15.1.0": ""}},
+			},
+		},
+		{
+			name:     "NeoForge",
+			options:  modrinth.ForgeUpdatesOptions{NeoForge: "only"},
+			query:    "neoforge=only",
+			status:   http.StatusOK,
+			body:     `{"homepage":"https://modrinth.com/mod/jei","promos":{}}`,
+			expected: &modrinth.ForgeUpdates{Homepage: "https://modrinth.com/mod/jei", Promos: map[string]string{}},
+		},
+		{name: "Not found", status: http.StatusNotFound, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/updates/jei/forge_updates.json" || r.URL.RawQuery != tt.query {
+					t.Errorf("request = %s", r.URL)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+			defer server.Close()
+
+			updates, err := client.GetForgeUpdates(context.Background(), "jei", tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetForgeUpdates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(updates, tt.expected) {
+				t.Errorf("GetForgeUpdates() = %+v, want %+v", updates, tt.expected)
+			}
+		})
+	}
+}
+
+func TestForgeUpdatesPromos(t *testing.T) {
+	var updates modrinth.ForgeUpdates
+	input := `{"homepage":"https://modrinth.com/mod/jei","promos":{"1.20.1-latest":"15.2.0"},"1.20.1":{"15.2.0":"Fixes"}}`
+	if err := json.Unmarshal([]byte(input), &updates); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if version, ok := updates.Latest("1.20.1"); !ok || version != "15.2.0" {
+		t.Errorf("Latest() = %q, %v", version, ok)
+	}
+	if version, ok := updates.Recommended("1.20.1"); ok {
+		t.Errorf("Recommended() = %q, want none", version)
+	}
+
+	output, err := json.Marshal(updates)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var want, got map[string]any
+	json.Unmarshal([]byte(input), &want)
+	json.Unmarshal(output, &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Marshal() = %s, want %s", output, input)
+	}
+}
@@ -0,0 +1,381 @@
+// Package imagecache downloads and caches project icons and gallery images on
+// disk, reports their dimensions and produces thumbnails.
+package imagecache
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options configures a Cache.
+type Options struct {
+	// MaxBytes is the total size of the cached files. The least recently used
+	// files are evicted beyond it. Defaults to 256 MiB.
+	MaxBytes int64
+	// MaxImageBytes is the size limit of one downloaded image. Defaults to 16 MiB.
+	MaxImageBytes int64
+	// Concurrency is the number of downloads made at once. Defaults to 4.
+	Concurrency int
+	// HTTPClient downloads the images. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Entry is a cached image.
+type Entry struct {
+	URL  string
+	Path string
+	Size int64
+	Info
+}
+
+type cacheItem struct {
+	key  string
+	size int64
+}
+
+type fetchCall struct {
+	done  chan struct{}
+	entry *Entry
+	err   error
+
+	// waiters counts the callers still waiting; the last one to give up
+	// cancels the shared fetch.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// Cache is a size-limited on-disk image cache with least recently used
+// eviction. Files are stored by the SHA-256 of their URL.
+type Cache struct {
+	dir     string
+	options Options
+	sem     chan struct{}
+
+	mu      sync.Mutex
+	lru     *list.List
+	items   map[string]*list.Element
+	total   int64
+	pending map[string]*fetchCall
+}
+
+// New opens the cache in the directory, creating it if needed, and indexes
+// the files already in it by their modification time.
+func New(dir string, options Options) (*Cache, error) {
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = 256 << 20
+	}
+	if options.MaxImageBytes <= 0 {
+		options.MaxImageBytes = 16 << 20
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 4
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = http.DefaultClient
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		dir:     dir,
+		options: options,
+		sem:     make(chan struct{}, options.Concurrency),
+		lru:     list.New(),
+		items:   map[string]*list.Element{},
+		pending: map[string]*fetchCall{},
+	}
+
+	type file struct {
+		key     string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".tmp-") {
+			return os.Remove(path)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, file{key: d.Name(), size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(files, func(a, b file) int { return b.modTime.Compare(a.modTime) })
+	for _, f := range files {
+		c.items[f.key] = c.lru.PushBack(&cacheItem{key: f.key, size: f.size})
+		c.total += f.size
+	}
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+	return c, nil
+}
+
+// Key returns the name a URL is stored under.
+func Key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// Size returns the total size of the cached files.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.total
+}
+
+// lookup returns the cached entry of a key and marks it as recently used.
+func (c *Cache) lookup(url, key string) (*Entry, bool) {
+	c.mu.Lock()
+	el, ok := c.items[key]
+	if ok {
+		c.lru.MoveToFront(el)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	path := c.path(key)
+	f, err := os.Open(path)
+	if err != nil {
+		c.remove(key)
+		return nil, false
+	}
+	defer f.Close()
+	info, err := DecodeInfo(f)
+	if err != nil {
+		c.remove(key)
+		return nil, false
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return &Entry{URL: url, Path: path, Size: el.Value.(*cacheItem).size, Info: info}, true
+}
+
+func (c *Cache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.total -= el.Value.(*cacheItem).size
+		c.lru.Remove(el)
+		delete(c.items, key)
+	}
+	os.Remove(c.path(key))
+}
+
+// evict removes the least recently used files until the cache fits. c.mu must be held.
+func (c *Cache) evict() {
+	for c.total > c.options.MaxBytes && c.lru.Len() > 1 {
+		el := c.lru.Back()
+		item := el.Value.(*cacheItem)
+		c.lru.Remove(el)
+		delete(c.items, item.key)
+		c.total -= item.size
+		os.Remove(c.path(item.key))
+	}
+}
+
+// store writes the data under the key and evicts older files if needed.
+func (c *Cache) store(url, key string, data []byte, info Info) (*Entry, error) {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	size := int64(len(data))
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.total -= el.Value.(*cacheItem).size
+		c.lru.Remove(el)
+	}
+	c.items[key] = c.lru.PushFront(&cacheItem{key: key, size: size})
+	c.total += size
+	c.evict()
+	c.mu.Unlock()
+	return &Entry{URL: url, Path: path, Size: size, Info: info}, nil
+}
+
+// once runs fn for a key unless it is already running, in which case it waits
+// for that result. fn gets a context that is only cancelled once every
+// caller's ctx is done, and each caller stops waiting when its own ctx is done.
+func (c *Cache) once(ctx context.Context, key string, fn func(ctx context.Context) (*Entry, error)) (*Entry, error) {
+	c.mu.Lock()
+	call, ok := c.pending[key]
+	if ok {
+		call.waiters++
+	} else {
+		shared, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &fetchCall{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		c.pending[key] = call
+		go func() {
+			defer cancel()
+			call.entry, call.err = fn(shared)
+			c.mu.Lock()
+			if c.pending[key] == call {
+				delete(c.pending, key)
+			}
+			c.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.entry, call.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			// Later callers start a fresh fetch instead of joining a cancelled one
+			if c.pending[key] == call {
+				delete(c.pending, key)
+			}
+		}
+		c.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// Get returns the cached image of the URL, downloading it first if needed.
+// Concurrent requests for the same URL share one download.
+func (c *Cache) Get(ctx context.Context, url string) (*Entry, error) {
+	key := Key(url)
+	if entry, ok := c.lookup(url, key); ok {
+		return entry, nil
+	}
+	return c.once(ctx, key, func(ctx context.Context) (*Entry, error) {
+		if entry, ok := c.lookup(url, key); ok {
+			return entry, nil
+		}
+		data, err := c.download(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		info, err := DecodeInfo(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", url, err)
+		}
+		return c.store(url, key, data, info)
+	})
+}
+
+func (c *Cache) download(ctx context.Context, url string) ([]byte, error) {
+	select {
+	case c.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-c.sem }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.options.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, c.options.MaxImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > c.options.MaxImageBytes {
+		return nil, fmt.Errorf("download %s: image larger than %d bytes", url, c.options.MaxImageBytes)
+	}
+	return data, nil
+}
+
+// Prefetch downloads the images of the URLs in parallel, for example the icons
+// of a page of search results. It returns the first error.
+func (c *Cache) Prefetch(ctx context.Context, urls []string) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(urls))
+	for i, url := range urls {
+		if url == "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = c.Get(ctx, url)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Thumbnail returns a cached thumbnail of the image that fits in the bounds,
+// creating it from the cached image if needed. A zero bound is unbounded.
+// WebP images cannot be thumbnailed and return ErrUnsupportedFormat.
+func (c *Cache) Thumbnail(ctx context.Context, url string, maxWidth, maxHeight int) (*Entry, error) {
+	key := Key(fmt.Sprintf("%s#thumbnail=%dx%d", url, maxWidth, maxHeight))
+	if entry, ok := c.lookup(url, key); ok {
+		return entry, nil
+	}
+	original, err := c.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return c.once(ctx, key, func(context.Context) (*Entry, error) {
+		data, err := os.ReadFile(original.Path)
+		if err != nil {
+			return nil, err
+		}
+		thumb, info, err := Thumbnail(data, maxWidth, maxHeight)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", url, err)
+		}
+		return c.store(url, key, thumb, info)
+	})
+}
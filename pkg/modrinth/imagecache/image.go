@@ -0,0 +1,177 @@
+package imagecache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// ErrNotImage is returned when the data is not a PNG, JPEG, GIF or WebP image.
+var ErrNotImage = errors.New("not a supported image")
+
+// ErrUnsupportedFormat is returned when thumbnailing a format the standard library cannot decode, such as WebP.
+var ErrUnsupportedFormat = errors.New("image format cannot be decoded")
+
+// maxPixels limits the size of images decoded for thumbnails.
+const maxPixels = 64 << 20
+
+// Info describes an image from its header.
+type Info struct {
+	// Format is png, jpeg, gif or webp.
+	Format string
+	Width  int
+	Height int
+}
+
+// DecodeInfo reads the format and dimensions of a PNG, JPEG, GIF or WebP
+// image from its header without decoding the pixels.
+func DecodeInfo(r io.Reader) (Info, error) {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(30)
+	switch {
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		config, err := png.DecodeConfig(br)
+		return Info{Format: "png", Width: config.Width, Height: config.Height}, err
+	case bytes.HasPrefix(header, []byte("\xff\xd8")):
+		config, err := jpeg.DecodeConfig(br)
+		return Info{Format: "jpeg", Width: config.Width, Height: config.Height}, err
+	case bytes.HasPrefix(header, []byte("GIF87a")), bytes.HasPrefix(header, []byte("GIF89a")):
+		config, err := gif.DecodeConfig(br)
+		return Info{Format: "gif", Width: config.Width, Height: config.Height}, err
+	case len(header) >= 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		return decodeWebPInfo(header)
+	}
+	return Info{}, ErrNotImage
+}
+
+// decodeWebPInfo reads the dimensions from the first chunk of a WebP file:
+// lossy (VP8), lossless (VP8L) or extended (VP8X).
+func decodeWebPInfo(header []byte) (Info, error) {
+	info := Info{Format: "webp"}
+	if len(header) < 30 {
+		return info, ErrNotImage
+	}
+	data := header[20:]
+	switch string(header[12:16]) {
+	case "VP8 ":
+		if data[3] != 0x9d || data[4] != 0x01 || data[5] != 0x2a {
+			return info, ErrNotImage
+		}
+		info.Width = int(binary.LittleEndian.Uint16(data[6:8]) & 0x3fff)
+		info.Height = int(binary.LittleEndian.Uint16(data[8:10]) & 0x3fff)
+	case "VP8L":
+		if data[0] != 0x2f {
+			return info, ErrNotImage
+		}
+		bits := binary.LittleEndian.Uint32(data[1:5])
+		info.Width = int(bits&0x3fff) + 1
+		info.Height = int(bits>>14&0x3fff) + 1
+	case "VP8X":
+		info.Width = int(uint32(data[4])|uint32(data[5])<<8|uint32(data[6])<<16) + 1
+		info.Height = int(uint32(data[7])|uint32(data[8])<<8|uint32(data[9])<<16) + 1
+	default:
+		return info, ErrNotImage
+	}
+	return info, nil
+}
+
+// decode decodes a PNG, JPEG or GIF image, refusing images too large to hold in memory.
+func decode(data []byte) (image.Image, Info, error) {
+	info, err := DecodeInfo(bytes.NewReader(data))
+	if err != nil {
+		return nil, info, err
+	}
+	if info.Width*info.Height > maxPixels {
+		return nil, info, errors.New("image is too large to decode")
+	}
+	var img image.Image
+	switch info.Format {
+	case "png":
+		img, err = png.Decode(bytes.NewReader(data))
+	case "jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	case "gif":
+		img, err = gif.Decode(bytes.NewReader(data))
+	default:
+		return nil, info, ErrUnsupportedFormat
+	}
+	return img, info, err
+}
+
+// fit returns the size of an image scaled down to fit in the bounds, keeping its aspect ratio.
+// A zero bound is unbounded. Images are never scaled up.
+func fit(width, height, maxWidth, maxHeight int) (int, int) {
+	scale := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 && height > maxHeight {
+		scale = min(scale, float64(maxHeight)/float64(height))
+	}
+	return max(1, int(float64(width)*scale+0.5)), max(1, int(float64(height)*scale+0.5))
+}
+
+// resize scales the image to the size with a box filter, averaging every
+// source pixel that falls into a destination pixel.
+func resize(src image.Image, width, height int) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := b.Min.Y + y*b.Dy()/height
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := b.Min.X + x*b.Dx()/width
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/width)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			if a == 0 {
+				continue
+			}
+			// The sums are premultiplied by alpha; divide by the alpha sum to get straight colors.
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r * 0xff / a),
+				G: uint8(g * 0xff / a),
+				B: uint8(bl * 0xff / a),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
+
+// Thumbnail decodes a PNG, JPEG or GIF image and scales it down to fit in the
+// bounds. JPEG images are encoded as JPEG and the others as PNG. Images that
+// already fit are returned unchanged.
+func Thumbnail(data []byte, maxWidth, maxHeight int) ([]byte, Info, error) {
+	img, info, err := decode(data)
+	if err != nil {
+		return nil, info, err
+	}
+	width, height := fit(info.Width, info.Height, maxWidth, maxHeight)
+	if width == info.Width && height == info.Height {
+		return data, info, nil
+	}
+	thumb := resize(img, width, height)
+	var buf bytes.Buffer
+	out := Info{Format: "png", Width: width, Height: height}
+	if info.Format == "jpeg" {
+		out.Format = "jpeg"
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, thumb)
+	}
+	return buf.Bytes(), out, err
+}
@@ -0,0 +1,240 @@
+package imagecache_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth/imagecache"
+)
+
+func encode(t *testing.T, format string, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func webp(t *testing.T, b64 string) []byte {
+	data, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecodeInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected imagecache.Info
+		wantErr  bool
+	}{
+		{name: "PNG", data: encode(t, "png", 12, 7), expected: imagecache.Info{Format: "png", Width: 12, Height: 7}},
+		{name: "JPEG", data: encode(t, "jpeg", 20, 10), expected: imagecache.Info{Format: "jpeg", Width: 20, Height: 10}},
+		{name: "GIF", data: encode(t, "gif", 5, 9), expected: imagecache.Info{Format: "gif", Width: 5, Height: 9}},
+		// 1x1 lossless WebP.
+		{name: "WebP lossless", data: webp(t, "UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA=="), expected: imagecache.Info{Format: "webp", Width: 1, Height: 1}},
+		// 1x1 lossy WebP.
+		{name: "WebP lossy", data: webp(t, "UklGRiIAAABXRUJQVlA4IBYAAAAwAQCdASoBAAEADsD+JaQAA3AAAAAA"), expected: imagecache.Info{Format: "webp", Width: 1, Height: 1}},
+		// 300x200 extended WebP header.
+		{name: "WebP extended", data: append([]byte("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00\x10\x00\x00\x00\x2b\x01\x00\xc7\x00\x00"), make([]byte, 8)...), expected: imagecache.Info{Format: "webp", Width: 300, Height: 200}},
+		{name: "Not an image", data: []byte("<html></html>"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := imagecache.DecodeInfo(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && info != tt.expected {
+				t.Errorf("DecodeInfo() = %+v, want %+v", info, tt.expected)
+			}
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	thumb, info, err := imagecache.Thumbnail(encode(t, "png", 200, 100), 50, 50)
+	if err != nil {
+		t.Fatalf("Thumbnail() error = %v", err)
+	}
+	if info != (imagecache.Info{Format: "png", Width: 50, Height: 25}) {
+		t.Errorf("Thumbnail() info = %+v", info)
+	}
+	img, err := png.Decode(bytes.NewReader(thumb))
+	if err != nil || img.Bounds().Dx() != 50 || img.Bounds().Dy() != 25 {
+		t.Errorf("Thumbnail() decoded = %v, %v", img.Bounds(), err)
+	}
+	if _, _, b, a := img.At(10, 10).RGBA(); b>>8 != 200 || a>>8 != 255 {
+		t.Errorf("Thumbnail() pixel blue = %d, alpha = %d, want 200, 255", b>>8, a>>8)
+	}
+
+	if _, _, err := imagecache.Thumbnail(webp(t, "UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA=="), 10, 10); err != imagecache.ErrUnsupportedFormat {
+		t.Errorf("Thumbnail(webp) error = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	images := map[string][]byte{
+		"/a.png": encode(t, "png", 64, 64),
+		"/b.png": encode(t, "png", 64, 32),
+		"/c.jpg": encode(t, "jpeg", 64, 64),
+	}
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/page.html" {
+			w.Write([]byte("<html></html>"))
+			return
+		}
+		w.Write(images[r.URL.Path])
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	limit := int64(len(images["/a.png"]) + len(images["/c.jpg"]) + 10)
+	cache, err := imagecache.New(dir, imagecache.Options{MaxBytes: limit})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.Get(ctx, server.URL+"/a.png"); err != nil {
+				t.Errorf("Get() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := requests.Load(); n != 1 {
+		t.Errorf("concurrent Get() made %d requests, want 1", n)
+	}
+
+	entry, err := cache.Get(ctx, server.URL+"/b.png")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if entry.Format != "png" || entry.Width != 64 || entry.Height != 32 {
+		t.Errorf("Get() = %+v", entry)
+	}
+	// Use a.png so b.png becomes the least recently used.
+	if _, err := cache.Get(ctx, server.URL+"/a.png"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := cache.Get(ctx, server.URL+"/c.jpg"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := os.Stat(entry.Path); !os.IsNotExist(err) {
+		t.Errorf("b.png was not evicted: %v", err)
+	}
+	if cache.Size() > limit {
+		t.Errorf("Size() = %d, want at most %d", cache.Size(), limit)
+	}
+
+	if _, err := cache.Get(ctx, server.URL+"/page.html"); err == nil {
+		t.Error("Get() of a non-image succeeded")
+	}
+
+	thumb, err := cache.Thumbnail(ctx, server.URL+"/c.jpg", 16, 16)
+	if err != nil {
+		t.Fatalf("Thumbnail() error = %v", err)
+	}
+	if thumb.Format != "jpeg" || thumb.Width != 16 || thumb.Height != 16 {
+		t.Errorf("Thumbnail() = %+v", thumb)
+	}
+
+	reopened, err := imagecache.New(dir, imagecache.Options{MaxBytes: limit})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if reopened.Size() != cache.Size() {
+		t.Errorf("reopened Size() = %d, want %d", reopened.Size(), cache.Size())
+	}
+	before := requests.Load()
+	if _, err := reopened.Thumbnail(ctx, server.URL+"/c.jpg", 16, 16); err != nil || requests.Load() != before {
+		t.Errorf("reopened Thumbnail() = %v, made %d requests, want it cached", err, requests.Load()-before)
+	}
+}
+
+func TestCacheSharedCancel(t *testing.T) {
+	png := encode(t, "png", 8, 8)
+	received := make(chan struct{}, 2)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		select {
+		case <-release:
+			w.Write(png)
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	cache, err := imagecache.New(t.TempDir(), imagecache.Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// The first caller starts the download and then gives up
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := cache.Get(ctx, server.URL+"/a.png")
+		first <- err
+	}()
+	<-received
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := cache.Get(context.Background(), server.URL+"/a.png")
+		second <- err
+	}()
+	// Give the second caller time to join the download in flight
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled Get() error = %v, want context.Canceled", err)
+	}
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("Get() error = %v, want the shared download to finish", err)
+	}
+	if len(received) != 0 {
+		t.Errorf("the second caller started its own download")
+	}
+}
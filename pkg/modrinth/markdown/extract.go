@@ -0,0 +1,67 @@
+package markdown
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// FirstImage returns the URL of the first http or https image in the Markdown,
+// either a Markdown image or an <img> tag, or "" if there is none.
+func FirstImage(src string) string {
+	var result string
+	Parse(src).Walk(func(n *Node) bool {
+		if result != "" {
+			return false
+		}
+		switch n.Kind {
+		case Image:
+			if u := cleanURL(n.Destination, ""); isExternal(u) {
+				result = u
+			}
+		case HTMLBlock, HTMLInline:
+			for _, tok := range tokenizeHTML(n.Literal) {
+				if tok.tag != "img" || tok.closing {
+					continue
+				}
+				if value, _ := tok.attr("src"); isExternal(cleanURL(value, "")) {
+					result = cleanURL(value, "")
+					break
+				}
+			}
+		}
+		return true
+	})
+	return result
+}
+
+// Summary returns the plain text of the first paragraph of the Markdown with
+// whitespace collapsed. If maxLen is positive and the text is longer, it is
+// cut at a word boundary and an ellipsis is appended.
+func Summary(src string, maxLen int) string {
+	var text string
+	Parse(src).Walk(func(n *Node) bool {
+		if text != "" {
+			return false
+		}
+		switch n.Kind {
+		case Paragraph:
+			text = strings.Join(strings.Fields(plainText(n)), " ")
+			return false
+		case HTMLBlock:
+			text = strings.Join(strings.Fields(htmlText(n.Literal)), " ")
+			return false
+		case CodeBlock, Table, Heading:
+			return false
+		}
+		return true
+	})
+	if maxLen <= 0 || utf8.RuneCountInString(text) <= maxLen {
+		return text
+	}
+	runes := []rune(text)
+	cut := string(runes[:maxLen])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 && runes[maxLen] != ' ' {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
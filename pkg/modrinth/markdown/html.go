@@ -0,0 +1,155 @@
+package markdown
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// RenderHTML renders Markdown as sanitized HTML.
+func RenderHTML(src string, options HTMLOptions) string {
+	var sb strings.Builder
+	writeHTML(&sb, Parse(src), false)
+	return Sanitize(sb.String(), options)
+}
+
+func writeHTMLChildren(sb *strings.Builder, n *Node, tight bool) {
+	for _, c := range n.Children {
+		writeHTML(sb, c, tight)
+	}
+}
+
+func writeHTML(sb *strings.Builder, n *Node, tight bool) {
+	switch n.Kind {
+	case Document:
+		writeHTMLChildren(sb, n, false)
+	case Paragraph:
+		if tight {
+			writeHTMLChildren(sb, n, false)
+			sb.WriteString("\n")
+			return
+		}
+		sb.WriteString("<p>")
+		writeHTMLChildren(sb, n, false)
+		sb.WriteString("</p>\n")
+	case Heading:
+		tag := "h" + strconv.Itoa(n.Level)
+		sb.WriteString("<" + tag + ">")
+		writeHTMLChildren(sb, n, false)
+		sb.WriteString("</" + tag + ">\n")
+	case CodeBlock:
+		sb.WriteString("<pre><code")
+		if lang := strings.Fields(n.Info); len(lang) > 0 {
+			sb.WriteString(` class="language-` + html.EscapeString(lang[0]) + `"`)
+		}
+		sb.WriteString(">" + html.EscapeString(n.Literal) + "</code></pre>\n")
+	case Blockquote:
+		sb.WriteString("<blockquote>\n")
+		writeHTMLChildren(sb, n, false)
+		sb.WriteString("</blockquote>\n")
+	case List:
+		tag := "ul"
+		if n.Ordered {
+			tag = "ol"
+		}
+		sb.WriteString("<" + tag)
+		if n.Ordered && n.Start != 1 {
+			sb.WriteString(` start="` + strconv.Itoa(n.Start) + `"`)
+		}
+		sb.WriteString(">\n")
+		for _, item := range n.Children {
+			sb.WriteString("<li>")
+			writeHTMLChildren(sb, item, n.Tight)
+			sb.WriteString("</li>\n")
+		}
+		sb.WriteString("</" + tag + ">\n")
+	case ThematicBreak:
+		sb.WriteString("<hr>\n")
+	case HTMLBlock:
+		sb.WriteString(n.Literal)
+	case Table:
+		sb.WriteString("<table>\n<thead>\n")
+		writeHTML(sb, n.Children[0], false)
+		sb.WriteString("</thead>\n")
+		if len(n.Children) > 1 {
+			sb.WriteString("<tbody>\n")
+			for _, row := range n.Children[1:] {
+				writeHTML(sb, row, false)
+			}
+			sb.WriteString("</tbody>\n")
+		}
+		sb.WriteString("</table>\n")
+	case TableRow:
+		tag := "td"
+		if n.Header {
+			tag = "th"
+		}
+		sb.WriteString("<tr>")
+		for _, cell := range n.Children {
+			sb.WriteString("<" + tag)
+			if cell.Align != "" {
+				sb.WriteString(` align="` + cell.Align + `"`)
+			}
+			sb.WriteString(">")
+			writeHTMLChildren(sb, cell, false)
+			sb.WriteString("</" + tag + ">")
+		}
+		sb.WriteString("</tr>\n")
+	case Text:
+		sb.WriteString(html.EscapeString(n.Literal))
+	case Emphasis:
+		sb.WriteString("<em>")
+		writeHTMLChildren(sb, n, false)
+		sb.WriteString("</em>")
+	case Strong:
+		sb.WriteString("<strong>")
+		writeHTMLChildren(sb, n, false)
+		sb.WriteString("</strong>")
+	case Strikethrough:
+		sb.WriteString("<del>")
+		writeHTMLChildren(sb, n, false)
+		sb.WriteString("</del>")
+	case Code:
+		sb.WriteString("<code>" + html.EscapeString(n.Literal) + "</code>")
+	case Link:
+		sb.WriteString(`<a href="` + html.EscapeString(n.Destination) + `"`)
+		if n.Title != "" {
+			sb.WriteString(` title="` + html.EscapeString(n.Title) + `"`)
+		}
+		sb.WriteString(">")
+		writeHTMLChildren(sb, n, false)
+		sb.WriteString("</a>")
+	case Image:
+		sb.WriteString(`<img src="` + html.EscapeString(n.Destination) + `" alt="` + html.EscapeString(plainText(n)) + `"`)
+		if n.Title != "" {
+			sb.WriteString(` title="` + html.EscapeString(n.Title) + `"`)
+		}
+		sb.WriteString(">")
+	case HTMLInline:
+		sb.WriteString(n.Literal)
+	case LineBreak:
+		sb.WriteString("<br>\n")
+	case SoftBreak:
+		sb.WriteString("\n")
+	}
+}
+
+// plainText returns the text content of a node without any formatting. The alt
+// text of nested images is left out.
+func plainText(n *Node) string {
+	var sb strings.Builder
+	n.Walk(func(c *Node) bool {
+		switch c.Kind {
+		case Image:
+			return c == n
+		case Text, Code:
+			sb.WriteString(c.Literal)
+		case SoftBreak, LineBreak:
+			sb.WriteString(" ")
+		case HTMLInline:
+			return false
+		}
+		return true
+	})
+	return sb.String()
+}
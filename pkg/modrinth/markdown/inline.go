@@ -0,0 +1,418 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	autolinkPattern   = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*)>`)
+	emailPattern      = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*)>`)
+	inlineHTMLPattern = regexp.MustCompile(`^(?:<[a-zA-Z][a-zA-Z0-9-]*(?:\s+[a-zA-Z_:][a-zA-Z0-9_.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*/?>|</[a-zA-Z][a-zA-Z0-9-]*\s*>|<!--[\s\S]*?-->)`)
+	bareURLPattern    = regexp.MustCompile(`^https?://[^\s<]+`)
+)
+
+const punctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+type inlineParser struct {
+	src   string
+	refs  map[string]linkRef
+	nodes []*Node
+	text  strings.Builder
+}
+
+func parseInline(src string, refs map[string]linkRef) []*Node {
+	p := &inlineParser{src: src, refs: refs}
+	p.parse()
+	return p.nodes
+}
+
+// flush turns the pending text into a Text node, decoding HTML entities.
+func (p *inlineParser) flush() {
+	if p.text.Len() == 0 {
+		return
+	}
+	p.appendText(html.UnescapeString(p.text.String()))
+	p.text.Reset()
+}
+
+func (p *inlineParser) appendText(s string) {
+	if n := len(p.nodes); n > 0 && p.nodes[n-1].Kind == Text {
+		p.nodes[n-1].Literal += s
+		return
+	}
+	p.nodes = append(p.nodes, &Node{Kind: Text, Literal: s})
+}
+
+func (p *inlineParser) add(n *Node) {
+	p.flush()
+	p.nodes = append(p.nodes, n)
+}
+
+func (p *inlineParser) parse() {
+	s := p.src
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch c {
+		case '\\':
+			if i+1 < len(s) && strings.IndexByte(punctuation, s[i+1]) >= 0 {
+				p.flush()
+				p.appendText(s[i+1 : i+2])
+				i += 2
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\n' {
+				p.trimTrailingSpaces()
+				p.add(&Node{Kind: LineBreak})
+				i += 2
+				continue
+			}
+		case '\n':
+			hard := strings.HasSuffix(p.text.String(), "  ")
+			p.trimTrailingSpaces()
+			if hard {
+				p.add(&Node{Kind: LineBreak})
+			} else {
+				p.add(&Node{Kind: SoftBreak})
+			}
+			i++
+			for i < len(s) && s[i] == ' ' {
+				i++
+			}
+			continue
+		case '`':
+			if n, next := parseCodeSpan(s, i); n != nil {
+				p.add(n)
+				i = next
+				continue
+			}
+			run := runLength(s, i, '`')
+			p.text.WriteString(s[i : i+run])
+			i += run
+			continue
+		case '*', '_', '~':
+			if n, next := p.parseDelimited(s, i); n != nil {
+				p.add(n)
+				i = next
+				continue
+			}
+			run := runLength(s, i, c)
+			p.text.WriteString(s[i : i+run])
+			i += run
+			continue
+		case '!':
+			if i+1 < len(s) && s[i+1] == '[' {
+				if n, next := p.parseLink(s, i+1, Image); n != nil {
+					p.add(n)
+					i = next
+					continue
+				}
+			}
+		case '[':
+			if n, next := p.parseLink(s, i, Link); n != nil {
+				p.add(n)
+				i = next
+				continue
+			}
+		case '<':
+			if m := autolinkPattern.FindStringSubmatch(s[i:]); m != nil {
+				p.add(&Node{Kind: Link, Destination: m[1], Children: []*Node{{Kind: Text, Literal: m[1]}}})
+				i += len(m[0])
+				continue
+			}
+			if m := emailPattern.FindStringSubmatch(s[i:]); m != nil {
+				p.add(&Node{Kind: Link, Destination: "mailto:" + m[1], Children: []*Node{{Kind: Text, Literal: m[1]}}})
+				i += len(m[0])
+				continue
+			}
+			if m := inlineHTMLPattern.FindString(s[i:]); m != "" {
+				p.add(&Node{Kind: HTMLInline, Literal: m})
+				i += len(m)
+				continue
+			}
+		case 'h':
+			if i == 0 || strings.IndexByte(" \n(*_~", s[i-1]) >= 0 {
+				if m := bareURLPattern.FindString(s[i:]); m != "" {
+					m = trimURL(m)
+					p.add(&Node{Kind: Link, Destination: m, Children: []*Node{{Kind: Text, Literal: m}}})
+					i += len(m)
+					continue
+				}
+			}
+		}
+		p.text.WriteByte(c)
+		i++
+	}
+	p.trimTrailingSpaces()
+	p.flush()
+}
+
+func (p *inlineParser) trimTrailingSpaces() {
+	t := strings.TrimRight(p.text.String(), " ")
+	p.text.Reset()
+	p.text.WriteString(t)
+}
+
+// trimURL removes trailing punctuation and unbalanced closing parentheses from a bare URL.
+func trimURL(u string) string {
+	for len(u) > 0 {
+		last := u[len(u)-1]
+		if strings.IndexByte("?!.,:*_~'\"", last) >= 0 {
+			u = u[:len(u)-1]
+			continue
+		}
+		if last == ')' && strings.Count(u, "(") < strings.Count(u, ")") {
+			u = u[:len(u)-1]
+			continue
+		}
+		break
+	}
+	return u
+}
+
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func parseCodeSpan(s string, i int) (*Node, int) {
+	n := runLength(s, i, '`')
+	for j := i + n; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		m := runLength(s, j, '`')
+		if m == n {
+			code := strings.ReplaceAll(s[i+n:j], "\n", " ")
+			if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			return &Node{Kind: Code, Literal: code}, j + m
+		}
+		j += m
+	}
+	return nil, i
+}
+
+func runeBefore(s string, i int) rune {
+	if i <= 0 {
+		return ' '
+	}
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return r
+}
+
+func runeAt(s string, i int) rune {
+	if i >= len(s) {
+		return ' '
+	}
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return r
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// findCloser finds the closing delimiter run of n characters c, starting the search at from.
+// Code spans and escaped characters are skipped, as are runs of a different length.
+func findCloser(s string, from int, c byte, n int) int {
+	for j := from; j < len(s); {
+		switch s[j] {
+		case '\\':
+			j += 2
+			continue
+		case '`':
+			if _, next := parseCodeSpan(s, j); next > j {
+				j = next
+				continue
+			}
+			j += runLength(s, j, '`')
+			continue
+		case c:
+			run := runLength(s, j, c)
+			closes := !unicode.IsSpace(runeBefore(s, j)) && j > from
+			if c == '_' && isWordRune(runeAt(s, j+run)) {
+				closes = false
+			}
+			if closes && (run == n || (n >= 2 && run > n && run < n+2)) {
+				return j
+			}
+			j += run
+			continue
+		}
+		j++
+	}
+	return -1
+}
+
+// parseDelimited parses emphasis, strong emphasis and strikethrough starting at i.
+func (p *inlineParser) parseDelimited(s string, i int) (*Node, int) {
+	c := s[i]
+	run := runLength(s, i, c)
+	if unicode.IsSpace(runeAt(s, i+run)) {
+		return nil, i
+	}
+	if c == '_' && isWordRune(runeBefore(s, i)) {
+		return nil, i
+	}
+	if c == '~' {
+		if run != 2 {
+			return nil, i
+		}
+		if j := findCloser(s, i+2, '~', 2); j >= 0 {
+			return &Node{Kind: Strikethrough, Children: parseInline(s[i+2:j], p.refs)}, j + 2
+		}
+		return nil, i
+	}
+	if run >= 3 {
+		if j := findCloser(s, i+3, c, 3); j >= 0 {
+			inner := &Node{Kind: Emphasis, Children: parseInline(s[i+3:j], p.refs)}
+			return &Node{Kind: Strong, Children: []*Node{inner}}, j + 3
+		}
+	}
+	if run >= 2 {
+		if j := findCloser(s, i+2, c, 2); j >= 0 {
+			return &Node{Kind: Strong, Children: parseInline(s[i+2:j], p.refs)}, j + 2
+		}
+	}
+	if run == 1 {
+		if j := findCloser(s, i+1, c, 1); j >= 0 {
+			return &Node{Kind: Emphasis, Children: parseInline(s[i+1:j], p.refs)}, j + 1
+		}
+	}
+	return nil, i
+}
+
+// matchBracket returns the index of the ']' closing the '[' at i, or -1.
+func matchBracket(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '`':
+			if _, next := parseCodeSpan(s, j); next > j {
+				j = next - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// parseDestination parses "(destination "title")" starting at the '(' at i.
+func parseDestination(s string, i int) (dest, title string, next int, ok bool) {
+	j := i + 1
+	for j < len(s) && (s[j] == ' ' || s[j] == '\n') {
+		j++
+	}
+	if j < len(s) && s[j] == '<' {
+		end := strings.IndexAny(s[j+1:], ">\n")
+		if end < 0 || s[j+1+end] != '>' {
+			return "", "", i, false
+		}
+		dest = s[j+1 : j+1+end]
+		j += end + 2
+	} else {
+		start, depth := j, 0
+		for ; j < len(s); j++ {
+			ch := s[j]
+			if ch == '\\' && j+1 < len(s) {
+				j++
+				continue
+			}
+			if ch == ' ' || ch == '\n' || ch < 0x20 {
+				break
+			}
+			if ch == '(' {
+				depth++
+			}
+			if ch == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		dest = s[start:j]
+	}
+	for j < len(s) && (s[j] == ' ' || s[j] == '\n') {
+		j++
+	}
+	if j < len(s) && (s[j] == '"' || s[j] == '\'' || s[j] == '(') {
+		closer := s[j]
+		if closer == '(' {
+			closer = ')'
+		}
+		end := strings.IndexByte(s[j+1:], closer)
+		if end < 0 {
+			return "", "", i, false
+		}
+		title = s[j+1 : j+1+end]
+		j += end + 2
+		for j < len(s) && (s[j] == ' ' || s[j] == '\n') {
+			j++
+		}
+	}
+	if j >= len(s) || s[j] != ')' {
+		return "", "", i, false
+	}
+	return unescapeLinkPart(dest), unescapeLinkPart(title), j + 1, true
+}
+
+func unescapeLinkPart(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(punctuation, s[i+1]) >= 0 {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return html.UnescapeString(sb.String())
+}
+
+// parseLink parses an inline or reference link or image whose '[' is at i.
+func (p *inlineParser) parseLink(s string, i int, kind NodeKind) (*Node, int) {
+	end := matchBracket(s, i)
+	if end < 0 {
+		return nil, i
+	}
+	label := s[i+1 : end]
+	node := &Node{Kind: kind}
+	if end+1 < len(s) && s[end+1] == '(' {
+		if dest, title, next, ok := parseDestination(s, end+1); ok {
+			node.Destination, node.Title = dest, title
+			node.Children = parseInline(label, p.refs)
+			return node, next
+		}
+	}
+	ref, next := label, end+1
+	if end+1 < len(s) && s[end+1] == '[' {
+		if close := strings.IndexByte(s[end+2:], ']'); close >= 0 {
+			if r := s[end+2 : end+2+close]; r != "" {
+				ref = r
+			}
+			next = end + 3 + close
+		}
+	}
+	if def, ok := p.refs[normalizeLabel(ref)]; ok {
+		node.Destination, node.Title = def.destination, def.title
+		node.Children = parseInline(label, p.refs)
+		return node, next
+	}
+	return nil, i
+}
@@ -0,0 +1,574 @@
+// Package markdown renders the Markdown of Modrinth project bodies and
+// changelogs as sanitized HTML or as plain or ANSI terminal text.
+//
+// It implements the subset of CommonMark and GitHub Flavored Markdown used on
+// Modrinth: headings, paragraphs, emphasis, strikethrough, code, block quotes,
+// lists, tables, links, images, autolinks and embedded HTML. Embedded HTML is
+// passed through Sanitize before it reaches the output.
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NodeKind is the kind of a Markdown node.
+type NodeKind int
+
+const (
+	Document NodeKind = iota
+	Paragraph
+	Heading
+	CodeBlock
+	Blockquote
+	List
+	ListItem
+	ThematicBreak
+	HTMLBlock
+	Table
+	TableRow
+	TableCell
+	Text
+	Emphasis
+	Strong
+	Strikethrough
+	Code
+	Link
+	Image
+	HTMLInline
+	LineBreak
+	SoftBreak
+)
+
+// Node is a node of a parsed Markdown document.
+type Node struct {
+	Kind     NodeKind
+	Children []*Node
+	// Literal is the content of Text, Code, CodeBlock, HTMLBlock and HTMLInline nodes.
+	Literal string
+	// Destination and Title belong to Link and Image nodes.
+	Destination string
+	Title       string
+	// Level is the level of a Heading.
+	Level int
+	// Info is the info string of a fenced CodeBlock, usually its language.
+	Info string
+	// Ordered, Start and Tight describe a List.
+	Ordered bool
+	Start   int
+	Tight   bool
+	// Header marks the header row of a Table.
+	Header bool
+	// Align is the alignment of a TableCell: left, center, right or empty.
+	Align string
+}
+
+// Walk calls fn for the node and its descendants in document order. If fn
+// returns false, the children of that node are skipped.
+func (n *Node) Walk(fn func(*Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, c := range n.Children {
+		c.Walk(fn)
+	}
+}
+
+type linkRef struct {
+	destination string
+	title       string
+}
+
+// Parse parses a Markdown document.
+func Parse(src string) *Node {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	src = strings.ReplaceAll(src, "\x00", "�")
+	lines := strings.Split(src, "\n")
+	for i, l := range lines {
+		lines[i] = expandTabs(l)
+	}
+	p := &blockParser{refs: make(map[string]linkRef)}
+	doc := &Node{Kind: Document, Children: p.parseBlocks(lines)}
+	p.parseInlines(doc)
+	return doc
+}
+
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var sb strings.Builder
+	col := 0
+	for _, r := range line {
+		if r == '\t' {
+			n := 4 - col%4
+			sb.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		sb.WriteRune(r)
+		col++
+	}
+	return sb.String()
+}
+
+var (
+	atxPattern        = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ ]+(.*?))?(?:[ ]+#+)?[ ]*$`)
+	thematicPattern   = regexp.MustCompile(`^ {0,3}(?:(?:\*[ ]*){3,}|(?:-[ ]*){3,}|(?:_[ ]*){3,})$`)
+	fencePattern      = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ ]*(.*)$")
+	blockquotePattern = regexp.MustCompile(`^ {0,3}> ?`)
+	listPattern       = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])( +|$)`)
+	setextPattern     = regexp.MustCompile(`^ {0,3}(=+|-+)[ ]*$`)
+	tableDelimPattern = regexp.MustCompile(`^ {0,3}\|?[ ]*:?-+:?[ ]*(?:\|[ ]*:?-+:?[ ]*)*\|?[ ]*$`)
+	refDefPattern     = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ ]*<?([^\s>]+)>?(?:[ ]+(?:"([^"]*)"|'([^']*)'|\(([^)]*)\)))?[ ]*$`)
+	htmlOpenPattern   = regexp.MustCompile(`^ {0,3}<(/?)([a-zA-Z][a-zA-Z0-9-]*)`)
+	htmlOnlyPattern   = regexp.MustCompile(`^ {0,3}(?:<[a-zA-Z][a-zA-Z0-9-]*(?:\s+[a-zA-Z_:][a-zA-Z0-9_.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*/?>|</[a-zA-Z][a-zA-Z0-9-]*\s*>)\s*$`)
+)
+
+// htmlBlockTags are the tags that start an HTML block and may interrupt a paragraph.
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true,
+	"center": true, "details": true, "dialog": true, "dd": true, "div": true, "dl": true,
+	"dt": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"head": true, "header": true, "hr": true, "html": true, "iframe": true, "legend": true,
+	"li": true, "main": true, "nav": true, "ol": true, "p": true, "section": true,
+	"summary": true, "table": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+	"thead": true, "tr": true, "ul": true,
+}
+
+// htmlRawTags are the tags whose HTML block lasts until the closing tag.
+var htmlRawTags = []string{"script", "pre", "style", "textarea"}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// stripIndent removes up to n leading spaces.
+func stripIndent(line string, n int) string {
+	i := 0
+	for i < n && i < len(line) && line[i] == ' ' {
+		i++
+	}
+	return line[i:]
+}
+
+type blockParser struct {
+	refs map[string]linkRef
+}
+
+type listMarker struct {
+	ordered       bool
+	char          byte
+	start         int
+	contentIndent int
+	empty         bool
+}
+
+func parseListMarker(line string) (listMarker, bool) {
+	m := listPattern.FindStringSubmatch(line)
+	if m == nil {
+		return listMarker{}, false
+	}
+	marker := listMarker{char: m[2][len(m[2])-1]}
+	if len(m[2]) > 1 || (m[2][0] >= '0' && m[2][0] <= '9') {
+		marker.ordered = true
+		marker.start, _ = strconv.Atoi(m[2][:len(m[2])-1])
+	}
+	spaces := len(m[3])
+	marker.empty = isBlank(line[len(m[0]):])
+	if spaces == 0 || spaces > 4 || marker.empty {
+		spaces = 1
+	}
+	marker.contentIndent = len(m[1]) + len(m[2]) + spaces
+	return marker, true
+}
+
+func htmlBlockKind(line string) (raw string, ok bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return "", false
+	}
+	if strings.HasPrefix(trimmed, "<!--") {
+		return "-->", true
+	}
+	lower := strings.ToLower(trimmed)
+	for _, tag := range htmlRawTags {
+		if strings.HasPrefix(lower, "<"+tag) && (len(lower) == len(tag)+1 || strings.ContainsAny(lower[len(tag)+1:len(tag)+2], " >\t")) {
+			return "</" + tag + ">", true
+		}
+	}
+	if m := htmlOpenPattern.FindStringSubmatch(line); m != nil && htmlBlockTags[strings.ToLower(m[2])] {
+		return "", true
+	}
+	if htmlOnlyPattern.MatchString(line) {
+		return "", true
+	}
+	return "", false
+}
+
+// interruptsParagraph reports whether the line starts a block that ends a paragraph.
+func interruptsParagraph(line string) bool {
+	if atxPattern.MatchString(line) || thematicPattern.MatchString(line) ||
+		fencePattern.MatchString(line) || blockquotePattern.MatchString(line) {
+		return true
+	}
+	if m, ok := parseListMarker(line); ok && !m.empty && (!m.ordered || m.start == 1) {
+		return true
+	}
+	if end, ok := htmlBlockKind(line); ok {
+		if end != "" {
+			return true
+		}
+		if m := htmlOpenPattern.FindStringSubmatch(line); m != nil && htmlBlockTags[strings.ToLower(m[2])] {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *blockParser) parseBlocks(lines []string) []*Node {
+	var nodes []*Node
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			i++
+			continue
+		}
+		if m := fencePattern.FindStringSubmatch(line); m != nil && !(m[2][0] == '`' && strings.Contains(m[3], "`")) {
+			node, next := parseFence(lines, i, m)
+			nodes = append(nodes, node)
+			i = next
+			continue
+		}
+		if m := atxPattern.FindStringSubmatch(line); m != nil {
+			nodes = append(nodes, &Node{Kind: Heading, Level: len(m[1]), Literal: strings.TrimSpace(m[2])})
+			i++
+			continue
+		}
+		if thematicPattern.MatchString(line) {
+			nodes = append(nodes, &Node{Kind: ThematicBreak})
+			i++
+			continue
+		}
+		if blockquotePattern.MatchString(line) {
+			node, next := p.parseBlockquote(lines, i)
+			nodes = append(nodes, node)
+			i = next
+			continue
+		}
+		if marker, ok := parseListMarker(line); ok {
+			node, next := p.parseList(lines, i, marker)
+			nodes = append(nodes, node)
+			i = next
+			continue
+		}
+		if end, ok := htmlBlockKind(line); ok {
+			node, next := parseHTMLBlock(lines, i, end)
+			nodes = append(nodes, node)
+			i = next
+			continue
+		}
+		if indentOf(line) >= 4 {
+			node, next := parseIndentedCode(lines, i)
+			nodes = append(nodes, node)
+			i = next
+			continue
+		}
+		if i+1 < len(lines) && strings.Contains(line, "|") && tableDelimPattern.MatchString(lines[i+1]) {
+			if node, next, ok := parseTable(lines, i); ok {
+				nodes = append(nodes, node)
+				i = next
+				continue
+			}
+		}
+		node, next := p.parseParagraph(lines, i)
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+		i = next
+	}
+	return nodes
+}
+
+func parseFence(lines []string, i int, m []string) (*Node, int) {
+	indent := len(m[1])
+	fence := m[2]
+	info := strings.TrimSpace(m[3])
+	if f := strings.Fields(info); len(f) > 0 {
+		info = f[0]
+	}
+	var content []string
+	j := i + 1
+	for ; j < len(lines); j++ {
+		l := lines[j]
+		trimmed := strings.TrimSpace(l)
+		if indentOf(l) <= 3 && strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			j++
+			break
+		}
+		content = append(content, stripIndent(l, indent))
+	}
+	literal := strings.Join(content, "\n")
+	if len(content) > 0 {
+		literal += "\n"
+	}
+	return &Node{Kind: CodeBlock, Info: info, Literal: literal}, j
+}
+
+func parseIndentedCode(lines []string, i int) (*Node, int) {
+	var content []string
+	j := i
+	for ; j < len(lines); j++ {
+		if !isBlank(lines[j]) && indentOf(lines[j]) < 4 {
+			break
+		}
+		content = append(content, stripIndent(lines[j], 4))
+	}
+	for len(content) > 0 && isBlank(content[len(content)-1]) {
+		content = content[:len(content)-1]
+	}
+	return &Node{Kind: CodeBlock, Literal: strings.Join(content, "\n") + "\n"}, j
+}
+
+func parseHTMLBlock(lines []string, i int, end string) (*Node, int) {
+	var content []string
+	j := i
+	for ; j < len(lines); j++ {
+		l := lines[j]
+		if end == "" && isBlank(l) {
+			break
+		}
+		content = append(content, l)
+		if end != "" && strings.Contains(strings.ToLower(l), end) {
+			j++
+			break
+		}
+	}
+	return &Node{Kind: HTMLBlock, Literal: strings.Join(content, "\n") + "\n"}, j
+}
+
+func (p *blockParser) parseBlockquote(lines []string, i int) (*Node, int) {
+	var content []string
+	j := i
+	for ; j < len(lines); j++ {
+		l := lines[j]
+		if loc := blockquotePattern.FindStringIndex(l); loc != nil {
+			content = append(content, l[loc[1]:])
+			continue
+		}
+		// Lazy continuation of a paragraph inside the quote.
+		if isBlank(l) || len(content) == 0 || isBlank(content[len(content)-1]) || interruptsParagraph(l) {
+			break
+		}
+		content = append(content, l)
+	}
+	return &Node{Kind: Blockquote, Children: p.parseBlocks(content)}, j
+}
+
+func sameListType(a, b listMarker) bool {
+	return a.ordered == b.ordered && a.char == b.char
+}
+
+func (p *blockParser) parseList(lines []string, i int, marker listMarker) (*Node, int) {
+	list := &Node{Kind: List, Ordered: marker.ordered, Start: marker.start, Tight: true}
+	j := i
+	for j < len(lines) {
+		m, ok := parseListMarker(lines[j])
+		if !ok || !sameListType(m, marker) {
+			break
+		}
+		var content []string
+		if !m.empty {
+			content = append(content, lines[j][m.contentIndent:])
+		}
+		j++
+		for j < len(lines) {
+			l := lines[j]
+			if isBlank(l) {
+				content = append(content, "")
+				j++
+				continue
+			}
+			if indentOf(l) >= m.contentIndent {
+				content = append(content, l[m.contentIndent:])
+				j++
+				continue
+			}
+			if len(content) > 0 && !isBlank(content[len(content)-1]) && !interruptsParagraph(l) {
+				if _, isItem := parseListMarker(l); !isItem {
+					content = append(content, strings.TrimLeft(l, " "))
+					j++
+					continue
+				}
+			}
+			break
+		}
+		trailingBlank := false
+		for len(content) > 0 && isBlank(content[len(content)-1]) {
+			content = content[:len(content)-1]
+			trailingBlank = true
+		}
+		item := &Node{Kind: ListItem, Children: p.parseBlocks(content)}
+		list.Children = append(list.Children, item)
+		if hasInnerBlank(content) {
+			list.Tight = false
+		}
+		if trailingBlank {
+			if next, ok := parseListMarker(lineAt(lines, j)); ok && sameListType(next, marker) {
+				list.Tight = false
+			} else {
+				break
+			}
+		}
+	}
+	return list, j
+}
+
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+// hasInnerBlank reports whether blank lines separate the top-level blocks of a list item.
+func hasInnerBlank(content []string) bool {
+	inFence := false
+	for i, l := range content {
+		if fencePattern.MatchString(l) {
+			inFence = !inFence
+		}
+		if !inFence && isBlank(l) && i > 0 && i < len(content)-1 && indentOf(content[i+1]) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func parseTable(lines []string, i int) (*Node, int, bool) {
+	header := splitTableRow(lines[i])
+	delims := splitTableRow(lines[i+1])
+	if len(header) != len(delims) {
+		return nil, i, false
+	}
+	aligns := make([]string, len(delims))
+	for k, d := range delims {
+		left, right := strings.HasPrefix(d, ":"), strings.HasSuffix(d, ":")
+		switch {
+		case left && right:
+			aligns[k] = "center"
+		case left:
+			aligns[k] = "left"
+		case right:
+			aligns[k] = "right"
+		}
+	}
+	row := func(cells []string, isHeader bool) *Node {
+		r := &Node{Kind: TableRow, Header: isHeader}
+		for k := range aligns {
+			var text string
+			if k < len(cells) {
+				text = cells[k]
+			}
+			r.Children = append(r.Children, &Node{Kind: TableCell, Align: aligns[k], Literal: text})
+		}
+		return r
+	}
+	table := &Node{Kind: Table, Children: []*Node{row(header, true)}}
+	j := i + 2
+	for ; j < len(lines); j++ {
+		l := lines[j]
+		if isBlank(l) || interruptsParagraph(l) {
+			break
+		}
+		table.Children = append(table.Children, row(splitTableRow(l), false))
+	}
+	return table, j, true
+}
+
+func (p *blockParser) parseParagraph(lines []string, i int) (*Node, int) {
+	var content []string
+	j := i
+	for ; j < len(lines); j++ {
+		l := lines[j]
+		if isBlank(l) {
+			break
+		}
+		if len(content) > 0 {
+			if m := setextPattern.FindStringSubmatch(l); m != nil {
+				level := 2
+				if m[1][0] == '=' {
+					level = 1
+				}
+				return &Node{Kind: Heading, Level: level, Literal: strings.Join(content, "\n")}, j + 1
+			}
+			if interruptsParagraph(l) {
+				break
+			}
+		}
+		content = append(content, strings.TrimLeft(l, " "))
+	}
+	// Link reference definitions at the start of a paragraph are not content.
+	for len(content) > 0 {
+		m := refDefPattern.FindStringSubmatch(content[0])
+		if m == nil {
+			break
+		}
+		label := normalizeLabel(m[1])
+		if _, ok := p.refs[label]; !ok {
+			p.refs[label] = linkRef{destination: m[2], title: m[3] + m[4] + m[5]}
+		}
+		content = content[1:]
+	}
+	if len(content) == 0 {
+		return nil, j
+	}
+	return &Node{Kind: Paragraph, Literal: strings.Join(content, "\n")}, j
+}
+
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// parseInlines replaces the raw text of paragraphs, headings and table cells with inline nodes.
+func (p *blockParser) parseInlines(n *Node) {
+	switch n.Kind {
+	case Paragraph, Heading, TableCell:
+		n.Children = parseInline(n.Literal, p.refs)
+		n.Literal = ""
+		return
+	}
+	for _, c := range n.Children {
+		p.parseInlines(c)
+	}
+}
@@ -0,0 +1,181 @@
+package markdown_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth/markdown"
+)
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		options  markdown.HTMLOptions
+		expected string
+	}{
+		{
+			name:     "Heading and emphasis",
+			src:      "# Title\n\nSome *italic*, **bold** and ~~old~~ `code`.",
+			expected: "<h1>Title</h1>\n<p>Some <em>italic</em>, <strong>bold</strong> and <del>old</del> <code>code</code>.</p>\n",
+		},
+		{
+			name:     "Tight list",
+			src:      "- one\n- two\n\n3. three\n4. four",
+			expected: "<ul>\n<li>one\n</li>\n<li>two\n</li>\n</ul>\n<ol start=\"3\">\n<li>three\n</li>\n<li>four\n</li>\n</ol>\n",
+		},
+		{
+			name:     "Fenced code",
+			src:      "```java\nint x = 1 < 2;\n```",
+			expected: "<pre><code class=\"language-java\">int x = 1 &lt; 2;\n</code></pre>\n",
+		},
+		{
+			name:     "External link",
+			src:      "[Wiki](https://example.com/wiki?utm_source=modrinth&page=2)",
+			options:  markdown.HTMLOptions{LinksInNewTab: true},
+			expected: "<p><a href=\"https://example.com/wiki?page=2\" rel=\"noopener noreferrer nofollow ugc\" target=\"_blank\">Wiki</a></p>\n",
+		},
+		{
+			name:     "Relative link with base URL",
+			src:      "[Gallery](gallery)",
+			options:  markdown.HTMLOptions{BaseURL: "https://modrinth.com/mod/sodium/"},
+			expected: "<p><a href=\"https://modrinth.com/mod/sodium/gallery\" rel=\"noopener noreferrer nofollow ugc\">Gallery</a></p>\n",
+		},
+		{
+			name:     "Reference link",
+			src:      "See [the docs][docs].\n\n[docs]: https://example.com \"Docs\"",
+			expected: "<p>See <a href=\"https://example.com\" title=\"Docs\" rel=\"noopener noreferrer nofollow ugc\">the docs</a>.</p>\n",
+		},
+		{
+			name:     "Rewritten image",
+			src:      "![Banner](https://cdn.example.com/banner.png)",
+			options:  markdown.HTMLOptions{RewriteImage: func(src string) string { return "/proxy?url=" + src }},
+			expected: "<p><img src=\"/proxy?url=https://cdn.example.com/banner.png\" alt=\"Banner\" loading=\"lazy\" decoding=\"async\"></p>\n",
+		},
+		{
+			name:     "Table",
+			src:      "| Loader | Supported |\n|:--|:-:|\n| Fabric | yes |",
+			expected: "<table>\n<thead>\n<tr><th align=\"left\">Loader</th><th align=\"center\">Supported</th></tr>\n</thead>\n<tbody>\n<tr><td align=\"left\">Fabric</td><td align=\"center\">yes</td></tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name:     "Embedded HTML",
+			src:      "<center>\n<img src=\"https://cdn.example.com/logo.png\" width=\"200\">\n</center>\n\nText",
+			expected: "<center>\n<img src=\"https://cdn.example.com/logo.png\" width=\"200\" loading=\"lazy\" decoding=\"async\">\n</center>\n<p>Text</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdown.RenderHTML(tt.src, tt.options); got != tt.expected {
+				t.Errorf("RenderHTML() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{name: "Script", src: "a<script>alert(1)</script>b", expected: "ab"},
+		{name: "Event handler", src: `<img src="https://example.com/a.png" onerror="alert(1)">`, expected: `<img src="https://example.com/a.png" loading="lazy" decoding="async">`},
+		{name: "Javascript link", src: `<a href=" java&#x09;script:alert(1)">x</a>`, expected: `<a>x</a>`},
+		{name: "Data image", src: `<img src="data:image/png;base64,AAAA">`, expected: ``},
+		{name: "Tracking pixel", src: `<img src="https://track.example.com/p.gif" width="1" height="1">`, expected: ``},
+		{name: "Style", src: `<p style="color:red">hi<style>p{}</style></p>`, expected: `<p>hi</p>`},
+		{name: "Unknown tag", src: `<marquee>hi</marquee>`, expected: `hi`},
+		{name: "Unbalanced", src: `<details><summary>More<b>bold`, expected: `<details><summary>More<b>bold</b></summary></details>`},
+		{name: "Nested", src: `<div><div>a</div>b</div>`, expected: `<div><div>a</div>b</div>`},
+		{name: "Comment", src: `a<!-- hidden -->b`, expected: `ab`},
+		{name: "YouTube embed", src: `<iframe src="https://www.youtube.com/embed/dQw4w9WgXcQ"></iframe>`, expected: `<a href="https://www.youtube.com/watch?v=dQw4w9WgXcQ" rel="noopener noreferrer nofollow ugc">https://www.youtube.com/watch?v=dQw4w9WgXcQ</a>`},
+		{name: "Code class", src: `<code class="language-go" id="x">x</code><code class="evil">y</code>`, expected: `<code class="language-go">x</code><code>y</code>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdown.Sanitize(tt.src, markdown.HTMLOptions{}); got != tt.expected {
+				t.Errorf("Sanitize() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	src := "# Changelog\n\n- Fixed **crash** on [startup](https://example.com/1)\n- Added `config`\n\n> Note\n\n---\n\n![shot](https://example.com/a.png)"
+	expected := "Changelog\n\n• Fixed crash on startup (https://example.com/1)\n• Added config\n\n│ Note\n\n────\n\n[image: shot]\n"
+	if got := markdown.RenderText(src, markdown.TextOptions{}); got != expected {
+		t.Errorf("RenderText() = %q, want %q", got, expected)
+	}
+
+	ansi := markdown.RenderText("**bold** \x1b[31mred", markdown.TextOptions{ANSI: true})
+	if expected := "\x1b[1mbold\x1b[0m [31mred\n"; ansi != expected {
+		t.Errorf("RenderText(ANSI) = %q, want %q", ansi, expected)
+	}
+
+	// Entities are decoded by the parser, so controls must not survive it either
+	ownCodes := strings.NewReplacer("\x1b[0m", "", "\x1b[1m", "", "\x1b[3m", "", "\x1b[4m", "", "\x1b[9m", "", "\x1b[34m", "", "\x1b[36m", "")
+	for _, src := range []string{
+		"a &#27;[31mred&#27;[0m b",
+		"<b>x&#x1b;]0;pwned&#x07;</b>",
+		"<div>&#x1b;[2J</div>\n\n&#x9b;31m `&#27;`",
+		"[link](https://example.com/&#27;[31m \"&#x1b;\")",
+	} {
+		for _, ansi := range []bool{false, true} {
+			got := markdown.RenderText(src, markdown.TextOptions{ANSI: ansi})
+			if strings.ContainsAny(ownCodes.Replace(got), "\x1b\x07\u009b") {
+				t.Errorf("RenderText(%q, ANSI: %v) = %q, contains a control character", src, ansi, got)
+			}
+		}
+	}
+
+	wrapped := markdown.RenderText("one two three four five six seven eight", markdown.TextOptions{Width: 15})
+	for _, line := range strings.Split(strings.TrimSpace(wrapped), "\n") {
+		if len(line) > 15 {
+			t.Errorf("RenderText(Width: 15) line %q is longer than 15", line)
+		}
+	}
+}
+
+func TestFirstImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{name: "Markdown image", src: "Intro\n\n![a](https://example.com/a.png) ![b](https://example.com/b.png)", expected: "https://example.com/a.png"},
+		{name: "HTML image", src: "<p align=\"center\"><img src=\"https://example.com/c.png\"></p>", expected: "https://example.com/c.png"},
+		{name: "Relative image skipped", src: "![a](a.png)\n\n![b](https://example.com/b.png)", expected: "https://example.com/b.png"},
+		{name: "No image", src: "Just text", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdown.FirstImage(tt.src); got != tt.expected {
+				t.Errorf("FirstImage() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		maxLen   int
+		expected string
+	}{
+		{name: "First paragraph", src: "# Sodium\n\n![banner](https://example.com/b.png)\n\nA **modern** rendering\nengine.\n\nMore text.", expected: "A modern rendering engine."},
+		{name: "Truncated", src: "A modern rendering engine for Minecraft.", maxLen: 20, expected: "A modern rendering…"},
+		{name: "HTML paragraph", src: "<p>Hello <b>world</b></p>", expected: "Hello world"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdown.Summary(tt.src, tt.maxLen); got != tt.expected {
+				t.Errorf("Summary() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// HTMLOptions controls how links and images are written by Sanitize and RenderHTML.
+type HTMLOptions struct {
+	// BaseURL resolves relative links and images. Relative URLs are kept as they are if empty.
+	BaseURL string
+	// RewriteLink, if set, rewrites the href of every kept link.
+	RewriteLink func(href string) string
+	// RewriteImage, if set, rewrites the src of every kept image, for example to
+	// route it through an image cache or proxy.
+	RewriteImage func(src string) string
+	// LinksInNewTab opens external links in a new tab.
+	LinksInNewTab bool
+}
+
+// droppedTags are removed together with their content.
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "object": true, "embed": true, "noscript": true,
+	"template": true, "textarea": true, "title": true, "head": true, "svg": true,
+	"math": true, "form": true, "iframe": true, "select": true, "button": true,
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+var blockAlignTags = []string{"p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "td", "th", "img", "table"}
+
+// allowedAttributes lists the attributes kept for every allowed tag.
+var allowedAttributes = map[string][]string{
+	"a": {"href", "title"}, "b": nil, "strong": nil, "i": nil, "em": nil, "u": nil,
+	"s": nil, "del": nil, "code": {"class"}, "pre": nil, "p": nil, "br": nil, "hr": nil,
+	"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+	"ul": nil, "ol": {"start"}, "li": nil, "blockquote": nil,
+	"img":   {"src", "alt", "title", "width", "height"},
+	"table": nil, "thead": nil, "tbody": nil, "tr": nil, "th": {"colspan", "rowspan"}, "td": {"colspan", "rowspan"},
+	"span": nil, "div": nil, "center": nil, "details": {"open"}, "summary": nil,
+	"sub": nil, "sup": nil, "kbd": nil,
+}
+
+// trackingParameters are removed from the query of every link and image.
+var trackingParameters = []string{"fbclid", "gclid", "dclid", "msclkid", "mc_cid", "mc_eid", "igshid", "yclid", "_hsenc", "_hsmi"}
+
+var (
+	tagPattern       = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9-]*)`)
+	attributePattern = regexp.MustCompile(`^\s*([^\s"'>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+)))?`)
+	numberPattern    = regexp.MustCompile(`^[0-9]{1,4}%?$`)
+	languagePattern  = regexp.MustCompile(`^language-[a-zA-Z0-9_+#-]+$`)
+	youtubePattern   = regexp.MustCompile(`^(?:https?:)?//(?:www\.)?(?:youtube(?:-nocookie)?\.com/embed/|youtu\.be/)([a-zA-Z0-9_-]{6,})`)
+)
+
+type htmlToken struct {
+	text    string // text content, or the raw tag if not a tag
+	tag     string
+	closing bool
+	self    bool
+	attrs   [][2]string
+}
+
+// tokenizeHTML splits HTML into text and tag tokens. Comments, doctypes and
+// processing instructions are dropped.
+func tokenizeHTML(s string) []htmlToken {
+	var tokens []htmlToken
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			tokens = append(tokens, htmlToken{text: text.String()})
+			text.Reset()
+		}
+	}
+	for i := 0; i < len(s); {
+		if s[i] != '<' {
+			text.WriteByte(s[i])
+			i++
+			continue
+		}
+		rest := s[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			flush()
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 7
+			continue
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			flush()
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+			continue
+		}
+		m := tagPattern.FindStringSubmatch(rest)
+		if m == nil {
+			text.WriteByte('<')
+			i++
+			continue
+		}
+		flush()
+		tok := htmlToken{tag: strings.ToLower(m[2]), closing: m[1] == "/"}
+		j := len(m[0])
+		for j < len(rest) {
+			for j < len(rest) && (rest[j] == ' ' || rest[j] == '\t' || rest[j] == '\n' || rest[j] == '/') {
+				if rest[j] == '/' {
+					tok.self = true
+				}
+				j++
+			}
+			if j >= len(rest) || rest[j] == '>' {
+				break
+			}
+			tok.self = false
+			a := attributePattern.FindStringSubmatch(rest[j:])
+			if a == nil {
+				j++
+				continue
+			}
+			tok.attrs = append(tok.attrs, [2]string{strings.ToLower(a[1]), html.UnescapeString(a[2] + a[3] + a[4])})
+			j += len(a[0])
+		}
+		if j < len(rest) {
+			j++
+		}
+		tok.text = rest[:j]
+		tokens = append(tokens, tok)
+		i += j
+	}
+	flush()
+	return tokens
+}
+
+func (t htmlToken) attr(name string) (string, bool) {
+	for _, a := range t.attrs {
+		if a[0] == name {
+			return a[1], true
+		}
+	}
+	return "", false
+}
+
+// cleanURL returns the URL if it is http, https, mailto or relative, resolved
+// against base and without tracking parameters. It returns "" otherwise.
+func cleanURL(raw, base string) string {
+	raw = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == ' ' {
+			return -1
+		}
+		return r
+	}, raw)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+	case "":
+		if strings.HasPrefix(raw, "#") {
+			return raw
+		}
+		if base != "" {
+			if b, err := url.Parse(base); err == nil {
+				u = b.ResolveReference(u)
+			}
+		}
+	default:
+		return ""
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		for key := range query {
+			if strings.HasPrefix(strings.ToLower(key), "utm_") || slices.Contains(trackingParameters, strings.ToLower(key)) {
+				query.Del(key)
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+func isExternal(href string) bool {
+	return strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") || strings.HasPrefix(href, "//")
+}
+
+// Sanitize removes everything but an allowlist of formatting tags and
+// attributes from HTML. Scripts, styles, frames and forms are dropped with
+// their content, links and images are limited to safe URLs with tracking
+// parameters removed, and tracking pixels are dropped. YouTube embeds are
+// replaced by a link to the video. The output is always balanced.
+func Sanitize(s string, options HTMLOptions) string {
+	var out strings.Builder
+	var open []string
+	skip, skipDepth := "", 0
+	for _, tok := range tokenizeHTML(s) {
+		if skip != "" {
+			if tok.tag == skip {
+				if tok.closing {
+					skipDepth--
+				} else if !tok.self {
+					skipDepth++
+				}
+				if skipDepth == 0 {
+					skip = ""
+				}
+			}
+			continue
+		}
+		if tok.tag == "" {
+			out.WriteString(html.EscapeString(html.UnescapeString(tok.text)))
+			continue
+		}
+		if droppedTags[tok.tag] {
+			if tok.closing {
+				continue
+			}
+			if tok.tag == "iframe" {
+				if src, _ := tok.attr("src"); youtubePattern.MatchString(src) {
+					id := youtubePattern.FindStringSubmatch(src)[1]
+					href := "https://www.youtube.com/watch?v=" + id
+					out.WriteString(`<a href="` + html.EscapeString(rewrite(href, options.RewriteLink)) + `"` + externalAttributes(options) + `>` + href + `</a>`)
+				}
+			}
+			if !tok.self {
+				skip, skipDepth = tok.tag, 1
+			}
+			continue
+		}
+		allowed, ok := allowedAttributes[tok.tag]
+		if !ok {
+			continue
+		}
+		if tok.closing {
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != tok.tag {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					out.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+			continue
+		}
+		attrs, keep := sanitizeAttributes(tok, allowed, options)
+		if !keep {
+			continue
+		}
+		out.WriteString("<" + tok.tag + attrs + ">")
+		if !voidTags[tok.tag] {
+			open = append(open, tok.tag)
+		}
+	}
+	for j := len(open) - 1; j >= 0; j-- {
+		out.WriteString("</" + open[j] + ">")
+	}
+	return out.String()
+}
+
+func rewrite(u string, fn func(string) string) string {
+	if fn == nil {
+		return u
+	}
+	return fn(u)
+}
+
+func externalAttributes(options HTMLOptions) string {
+	attrs := ` rel="noopener noreferrer nofollow ugc"`
+	if options.LinksInNewTab {
+		attrs += ` target="_blank"`
+	}
+	return attrs
+}
+
+// sanitizeAttributes writes the allowed attributes of a start tag. It returns
+// false if the whole element should be dropped.
+func sanitizeAttributes(tok htmlToken, allowed []string, options HTMLOptions) (string, bool) {
+	var sb strings.Builder
+	write := func(name, value string) {
+		sb.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+	}
+	for _, a := range tok.attrs {
+		name, value := a[0], a[1]
+		switch {
+		case name == "align" && slices.Contains(blockAlignTags, tok.tag):
+			if v := strings.ToLower(value); v == "left" || v == "center" || v == "right" || v == "justify" {
+				write(name, v)
+			}
+		case !slices.Contains(allowed, name):
+		case name == "href":
+			href := cleanURL(value, options.BaseURL)
+			if href == "" {
+				continue
+			}
+			write(name, rewrite(href, options.RewriteLink))
+		case name == "src":
+			src := cleanURL(value, options.BaseURL)
+			if src == "" || strings.HasPrefix(src, "mailto:") {
+				return "", false
+			}
+			write(name, rewrite(src, options.RewriteImage))
+		case name == "width", name == "height", name == "colspan", name == "rowspan", name == "start":
+			if numberPattern.MatchString(value) {
+				write(name, value)
+			}
+		case name == "class":
+			if languagePattern.MatchString(value) {
+				write(name, value)
+			}
+		case name == "open":
+			sb.WriteString(" open")
+		default:
+			write(name, value)
+		}
+	}
+	switch tok.tag {
+	case "img":
+		if _, ok := tok.attr("src"); !ok {
+			return "", false
+		}
+		if !strings.Contains(sb.String(), ` src="`) {
+			return "", false
+		}
+		width, _ := tok.attr("width")
+		height, _ := tok.attr("height")
+		if (width == "0" || width == "1") && (height == "0" || height == "1") {
+			return "", false
+		}
+		sb.WriteString(` loading="lazy" decoding="async"`)
+	case "a":
+		if href, _ := tok.attr("href"); isExternal(cleanURL(href, options.BaseURL)) {
+			sb.WriteString(externalAttributes(options))
+		}
+	}
+	return sb.String(), true
+}
@@ -0,0 +1,275 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TextOptions controls RenderText.
+type TextOptions struct {
+	// ANSI styles headings, emphasis, code and links with ANSI escape sequences.
+	ANSI bool
+	// Width wraps paragraphs to the given number of columns. Zero disables wrapping.
+	Width int
+}
+
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiItalic    = "\x1b[3m"
+	ansiUnderline = "\x1b[4m"
+	ansiStrike    = "\x1b[9m"
+	ansiCyan      = "\x1b[36m"
+	ansiBlue      = "\x1b[34m"
+)
+
+var (
+	ansiPattern     = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	breakTagPattern = regexp.MustCompile(`(?i)<br\s*/?>|</?(?:p|div|li|tr|h[1-6]|center|details|summary)\b[^>]*>`)
+	anyTagPattern   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// RenderText renders Markdown as terminal text, with ANSI styles if requested.
+// Embedded HTML is sanitized and reduced to its text, and control characters
+// are removed after entities are decoded, so neither raw nor encoded escape
+// sequences such as &#27; reach the terminal.
+func RenderText(src string, options TextOptions) string {
+	doc := Parse(src)
+	doc.Walk(func(n *Node) bool {
+		n.Literal = stripControls(n.Literal)
+		n.Destination = stripControls(n.Destination)
+		n.Title = stripControls(n.Title)
+		n.Info = stripControls(n.Info)
+		return true
+	})
+	r := &textRenderer{options: options}
+	blocks := r.blocks(doc.Children, "")
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// stripControls removes C0 and C1 control characters except newlines and tabs.
+func stripControls(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r < 0x20 && r != '\n' && r != '\t' && r != '\r') || (r >= 0x7f && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+type textRenderer struct {
+	options TextOptions
+}
+
+func (r *textRenderer) style(s string, codes ...string) string {
+	if !r.options.ANSI || s == "" {
+		return s
+	}
+	return strings.Join(codes, "") + s + ansiReset
+}
+
+// blocks renders block nodes, each wrapped to the width left after the indent.
+func (r *textRenderer) blocks(nodes []*Node, indent string) []string {
+	var result []string
+	for _, n := range nodes {
+		if b := r.block(n, indent); b != "" {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+func (r *textRenderer) wrap(s string, indent int) string {
+	width := r.options.Width - indent
+	if r.options.Width <= 0 || width < 10 {
+		return s
+	}
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		var current string
+		for _, word := range strings.Fields(line) {
+			switch {
+			case current == "":
+				current = word
+			case visibleWidth(current)+1+visibleWidth(word) > width:
+				lines = append(lines, current)
+				current = word
+			default:
+				current += " " + word
+			}
+		}
+		lines = append(lines, current)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiPattern.ReplaceAllString(s, ""))
+}
+
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		p := rest
+		if i == 0 {
+			p = first
+		}
+		if l == "" {
+			lines[i] = strings.TrimRight(p, " ")
+		} else {
+			lines[i] = p + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *textRenderer) block(n *Node, indent string) string {
+	switch n.Kind {
+	case Paragraph:
+		return r.wrap(r.inlines(n.Children), visibleWidth(indent))
+	case Heading:
+		text := r.wrap(r.inlines(n.Children), visibleWidth(indent))
+		if n.Level == 1 {
+			return r.style(text, ansiBold, ansiUnderline)
+		}
+		return r.style(text, ansiBold)
+	case CodeBlock:
+		code := strings.TrimRight(n.Literal, "\n")
+		return prefixLines(r.style(code, ansiCyan), "    ", "    ")
+	case Blockquote:
+		inner := strings.Join(r.blocks(n.Children, indent+"│ "), "\n\n")
+		return prefixLines(inner, "│ ", "│ ")
+	case List:
+		var items []string
+		for i, item := range n.Children {
+			marker := "• "
+			if n.Ordered {
+				marker = strconv.Itoa(n.Start+i) + ". "
+			}
+			pad := strings.Repeat(" ", utf8.RuneCountInString(marker))
+			sep := "\n\n"
+			if n.Tight {
+				sep = "\n"
+			}
+			inner := strings.Join(r.blocks(item.Children, indent+pad), sep)
+			items = append(items, prefixLines(inner, marker, pad))
+		}
+		if n.Tight {
+			return strings.Join(items, "\n")
+		}
+		return strings.Join(items, "\n\n")
+	case ThematicBreak:
+		return "────"
+	case HTMLBlock:
+		return r.wrap(htmlText(n.Literal), visibleWidth(indent))
+	case Table:
+		return r.table(n)
+	}
+	return ""
+}
+
+func (r *textRenderer) table(n *Node) string {
+	var rows [][]string
+	var widths []int
+	for _, row := range n.Children {
+		var cells []string
+		for k, cell := range row.Children {
+			text := r.inlines(cell.Children)
+			if row.Header {
+				text = r.style(text, ansiBold)
+			}
+			cells = append(cells, text)
+			if k >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[k] = max(widths[k], visibleWidth(text))
+		}
+		rows = append(rows, cells)
+	}
+	var lines []string
+	for i, cells := range rows {
+		var sb strings.Builder
+		for k, text := range cells {
+			if k > 0 {
+				sb.WriteString(" │ ")
+			}
+			pad := widths[k] - visibleWidth(text)
+			switch n.Children[i].Children[k].Align {
+			case "right":
+				sb.WriteString(strings.Repeat(" ", pad) + text)
+			case "center":
+				sb.WriteString(strings.Repeat(" ", pad/2) + text + strings.Repeat(" ", pad-pad/2))
+			default:
+				sb.WriteString(text + strings.Repeat(" ", pad))
+			}
+		}
+		lines = append(lines, strings.TrimRight(sb.String(), " "))
+		if i == 0 {
+			var rule []string
+			for _, w := range widths {
+				rule = append(rule, strings.Repeat("─", w))
+			}
+			lines = append(lines, strings.Join(rule, "─┼─"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *textRenderer) inlines(nodes []*Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		switch n.Kind {
+		case Text:
+			sb.WriteString(n.Literal)
+		case Emphasis:
+			sb.WriteString(r.style(r.inlines(n.Children), ansiItalic))
+		case Strong:
+			sb.WriteString(r.style(r.inlines(n.Children), ansiBold))
+		case Strikethrough:
+			sb.WriteString(r.style(r.inlines(n.Children), ansiStrike))
+		case Code:
+			sb.WriteString(r.style(n.Literal, ansiCyan))
+		case Link:
+			text := r.inlines(n.Children)
+			dest := cleanURL(n.Destination, "")
+			switch {
+			case dest == "" || text == dest || "mailto:"+text == dest:
+				sb.WriteString(r.style(text, ansiUnderline))
+			default:
+				sb.WriteString(r.style(text, ansiUnderline) + " (" + r.style(dest, ansiBlue) + ")")
+			}
+		case Image:
+			if alt := plainText(n); alt != "" {
+				sb.WriteString("[image: " + alt + "]")
+			} else {
+				sb.WriteString("[image]")
+			}
+		case HTMLInline:
+			if strings.HasPrefix(strings.ToLower(n.Literal), "<br") {
+				sb.WriteString("\n")
+			}
+		case LineBreak:
+			sb.WriteString("\n")
+		case SoftBreak:
+			sb.WriteString(" ")
+		}
+	}
+	return sb.String()
+}
+
+// htmlText reduces sanitized HTML to its text, turning line and block breaks into newlines.
+func htmlText(s string) string {
+	s = Sanitize(s, HTMLOptions{})
+	s = breakTagPattern.ReplaceAllString(s, "\n")
+	s = stripControls(html.UnescapeString(anyTagPattern.ReplaceAllString(s, "")))
+	var lines []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.Join(strings.Fields(l), " "); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,124 @@
+package modrinth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	// MavenRepository is the URL of Modrinth's Maven repository, which
+	// maven.modrinth.com redirects to.
+	MavenRepository = "https://api.modrinth.com/maven"
+	// MavenGroup is the group ID of every artifact in the repository.
+	MavenGroup = "maven.modrinth"
+)
+
+// MavenCoordinate identifies an artifact in a Maven repository.
+type MavenCoordinate struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+	Classifier string
+	// Extension is the file extension. Empty means "jar".
+	Extension string
+}
+
+// ParseMavenCoordinate parses a coordinate in Gradle notation, such as
+// "maven.modrinth:sodium:mc1.20.1-0.5.3" or "group:artifact:version:classifier@zip".
+func ParseMavenCoordinate(s string) (MavenCoordinate, error) {
+	var m MavenCoordinate
+	rest, ext, hasExt := strings.Cut(s, "@")
+	if hasExt {
+		if ext == "" {
+			return m, fmt.Errorf("invalid maven coordinate %q: empty extension", s)
+		}
+		m.Extension = ext
+	}
+	parts := strings.Split(rest, ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return m, fmt.Errorf("invalid maven coordinate %q: want group:artifact:version[:classifier]", s)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return m, fmt.Errorf("invalid maven coordinate %q: empty part", s)
+		}
+	}
+	m.GroupID, m.ArtifactID, m.Version = parts[0], parts[1], parts[2]
+	if len(parts) == 4 {
+		m.Classifier = parts[3]
+	}
+	return m, nil
+}
+
+// String returns the coordinate in Gradle notation.
+func (m MavenCoordinate) String() string {
+	s := m.GroupID + ":" + m.ArtifactID + ":" + m.Version
+	if m.Classifier != "" {
+		s += ":" + m.Classifier
+	}
+	if m.Extension != "" && m.Extension != "jar" {
+		s += "@" + m.Extension
+	}
+	return s
+}
+
+// FileName returns the name of the artifact file.
+func (m MavenCoordinate) FileName() string {
+	name := m.ArtifactID + "-" + m.Version
+	if m.Classifier != "" {
+		name += "-" + m.Classifier
+	}
+	ext := m.Extension
+	if ext == "" {
+		ext = "jar"
+	}
+	return name + "." + ext
+}
+
+// Path returns the path of the artifact file in a repository.
+func (m MavenCoordinate) Path() string {
+	segments := strings.Split(m.GroupID, ".")
+	segments = append(segments, m.ArtifactID, m.Version, m.FileName())
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return path.Join(segments...)
+}
+
+// URL returns the URL of the artifact file in the repository, or in
+// MavenRepository if repository is empty.
+func (m MavenCoordinate) URL(repository string) string {
+	if repository == "" {
+		repository = MavenRepository
+	}
+	return strings.TrimSuffix(repository, "/") + "/" + m.Path()
+}
+
+// VersionMavenCoordinate returns the coordinate of the primary file of a
+// version. The artifact is the project slug or ID, and falls back to the
+// version's project ID if empty.
+func VersionMavenCoordinate(project string, version *ProjectVersion) MavenCoordinate {
+	if project == "" {
+		project = version.ProjectID
+	}
+	m := MavenCoordinate{GroupID: MavenGroup, ArtifactID: project, Version: version.VersionNumber}
+	if file := version.PrimaryFile(); file != nil {
+		if ext := path.Ext(file.Filename); ext != "" && ext != ".jar" {
+			m.Extension = ext[1:]
+		}
+	}
+	return m
+}
+
+// GetVersionFromMaven fetches the project version a coordinate in the
+// Modrinth repository refers to. The version of the coordinate may be a
+// version number or ID.
+func (c *ModrinthV2Client) GetVersionFromMaven(ctx context.Context, coordinate MavenCoordinate) (*ProjectVersion, error) {
+	if coordinate.GroupID != MavenGroup {
+		return nil, fmt.Errorf("maven coordinate %s is not in group %s", coordinate, MavenGroup)
+	}
+	return c.GetVersionByNumber(ctx, coordinate.ArtifactID, coordinate.Version)
+}
@@ -0,0 +1,98 @@
+package modrinth_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+func TestParseMavenCoordinate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected modrinth.MavenCoordinate
+		path     string
+		wantErr  bool
+	}{
+		{
+			input:    "maven.modrinth:sodium:mc1.20.1-0.5.3",
+			expected: modrinth.MavenCoordinate{GroupID: "maven.modrinth", ArtifactID: "sodium", Version: "mc1.20.1-0.5.3"},
+			path:     "maven/modrinth/sodium/mc1.20.1-0.5.3/sodium-mc1.20.1-0.5.3.jar",
+		},
+		{
+			input:    "maven.modrinth:fabulously-optimized:5.0.0+1.20:client@mrpack",
+			expected: modrinth.MavenCoordinate{GroupID: "maven.modrinth", ArtifactID: "fabulously-optimized", Version: "5.0.0+1.20", Classifier: "client", Extension: "mrpack"},
+			path:     "maven/modrinth/fabulously-optimized/5.0.0+1.20/fabulously-optimized-5.0.0+1.20-client.mrpack",
+		},
+		{input: "maven.modrinth:sodium", wantErr: true},
+		{input: "maven.modrinth::1.0", wantErr: true},
+		{input: "maven.modrinth:sodium:1.0@", wantErr: true},
+		{input: "a:b:c:d:e", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			m, err := modrinth.ParseMavenCoordinate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMavenCoordinate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if m != tt.expected {
+				t.Errorf("ParseMavenCoordinate() = %+v, want %+v", m, tt.expected)
+			}
+			if m.String() != tt.input {
+				t.Errorf("String() = %q, want %q", m.String(), tt.input)
+			}
+			if m.Path() != tt.path {
+				t.Errorf("Path() = %q, want %q", m.Path(), tt.path)
+			}
+		})
+	}
+}
+
+func TestVersionMavenCoordinate(t *testing.T) {
+	version := &modrinth.ProjectVersion{
+		ProjectID:     "AANobbMI",
+		VersionNumber: "mc1.20.1-0.5.3",
+		Files: []modrinth.VersionFile{
+			{Filename: "sodium-sources.jar"},
+			{Filename: "sodium-fabric.jar", Primary: true},
+		},
+	}
+	m := modrinth.VersionMavenCoordinate("", version)
+	if expected := "maven.modrinth:AANobbMI:mc1.20.1-0.5.3"; m.String() != expected {
+		t.Errorf("VersionMavenCoordinate() = %s, want %s", m, expected)
+	}
+	if expected := "https://api.modrinth.com/maven/maven/modrinth/AANobbMI/mc1.20.1-0.5.3/AANobbMI-mc1.20.1-0.5.3.jar"; m.URL("") != expected {
+		t.Errorf("URL() = %s, want %s", m.URL(""), expected)
+	}
+
+	pack := &modrinth.ProjectVersion{VersionNumber: "5.0.0", Files: []modrinth.VersionFile{{Filename: "Pack 5.0.0.mrpack", Primary: true}}}
+	if m := modrinth.VersionMavenCoordinate("pack", pack); m.String() != "maven.modrinth:pack:5.0.0@mrpack" {
+		t.Errorf("VersionMavenCoordinate() = %s", m)
+	}
+}
+
+func TestGetVersionFromMaven(t *testing.T) {
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/project/sodium/version/mc1.20.1-0.5.3" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"id":"IIJJKKLL","version_number":"mc1.20.1-0.5.3"}`)
+	})
+	defer server.Close()
+
+	m, _ := modrinth.ParseMavenCoordinate("maven.modrinth:sodium:mc1.20.1-0.5.3")
+	version, err := client.GetVersionFromMaven(context.Background(), m)
+	if err != nil || version.ID != "IIJJKKLL" {
+		t.Errorf("GetVersionFromMaven() = %+v, %v", version, err)
+	}
+
+	if _, err := client.GetVersionFromMaven(context.Background(), modrinth.MavenCoordinate{GroupID: "net.fabricmc", ArtifactID: "fabric-loader", Version: "0.15.0"}); err == nil {
+		t.Error("GetVersionFromMaven() of another group succeeded")
+	}
+}
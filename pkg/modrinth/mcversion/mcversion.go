@@ -0,0 +1,333 @@
+// Package mcversion parses and compares Minecraft game versions and mod versions.
+//
+// Game versions are best ordered with the Modrinth game version tags, see
+// NewOrdering. When the tags are not available, Compare falls back to an
+// offline heuristic based on the version format and known release dates.
+package mcversion
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the kind of a Minecraft version.
+type Kind int
+
+const (
+	// Unknown is a version that does not follow any known format.
+	Unknown Kind = iota
+	// PreClassic is a pre-classic version, e.g. rd-132211.
+	PreClassic
+	// Classic is a classic version, e.g. c0.30_01c.
+	Classic
+	// Infdev is an indev or infdev version, e.g. inf-20100618.
+	Infdev
+	// Alpha is an alpha version, e.g. a1.2.6.
+	Alpha
+	// Beta is a beta version, e.g. b1.7.3.
+	Beta
+	// Snapshot is a snapshot, e.g. 24w14a or 26.1-snapshot-1.
+	Snapshot
+	// PreRelease is a pre-release, e.g. 1.21-pre3.
+	PreRelease
+	// ReleaseCandidate is a release candidate, e.g. 1.21-rc1.
+	ReleaseCandidate
+	// Release is a release, e.g. 1.20.1.
+	Release
+)
+
+// Version is a parsed Minecraft version.
+type Version struct {
+	Raw  string
+	Kind Kind
+	// Numbers are the numeric components, e.g. [1 20 1] for 1.20.1 and its
+	// pre-releases. Weekly snapshots have no numbers.
+	Numbers []int
+	// Pre is the snapshot, pre-release or release candidate number.
+	Pre int
+	// Year and Week are the two digit year and the week of a weekly snapshot.
+	Year, Week int
+	// Suffix is the letter suffix of a weekly snapshot, e.g. "a" for 24w14a.
+	Suffix string
+}
+
+var (
+	releasePattern  = regexp.MustCompile(`^(\d+(?:\.\d+)*)$`)
+	prePattern      = regexp.MustCompile(`^(\d+(?:\.\d+)*)(?:-pre| Pre-Release )(\d+)$`)
+	rcPattern       = regexp.MustCompile(`^(\d+(?:\.\d+)*)-rc(\d+)$`)
+	newSnapPattern  = regexp.MustCompile(`^(\d+(?:\.\d+)*)-snapshot-(\d+)$`)
+	snapshotPattern = regexp.MustCompile(`^(\d\d)w(\d\d)([a-z~]+)$`)
+	digitsPattern   = regexp.MustCompile(`\d+`)
+)
+
+// Parse parses a Minecraft version.
+func Parse(s string) (Version, error) {
+	v := Version{Raw: s}
+	if m := releasePattern.FindStringSubmatch(s); m != nil {
+		v.Kind = Release
+		v.Numbers = splitNumbers(m[1])
+		return v, nil
+	}
+	if m := prePattern.FindStringSubmatch(s); m != nil {
+		v.Kind = PreRelease
+		v.Numbers = splitNumbers(m[1])
+		v.Pre, _ = strconv.Atoi(m[2])
+		return v, nil
+	}
+	if m := rcPattern.FindStringSubmatch(s); m != nil {
+		v.Kind = ReleaseCandidate
+		v.Numbers = splitNumbers(m[1])
+		v.Pre, _ = strconv.Atoi(m[2])
+		return v, nil
+	}
+	if m := newSnapPattern.FindStringSubmatch(s); m != nil {
+		v.Kind = Snapshot
+		v.Numbers = splitNumbers(m[1])
+		v.Pre, _ = strconv.Atoi(m[2])
+		return v, nil
+	}
+	if m := snapshotPattern.FindStringSubmatch(s); m != nil {
+		v.Kind = Snapshot
+		v.Year, _ = strconv.Atoi(m[1])
+		v.Week, _ = strconv.Atoi(m[2])
+		v.Suffix = m[3]
+		return v, nil
+	}
+	switch {
+	case strings.HasPrefix(s, "rd-"):
+		v.Kind = PreClassic
+	case strings.HasPrefix(s, "c0."):
+		v.Kind = Classic
+	case strings.HasPrefix(s, "inf-"), strings.HasPrefix(s, "in-"):
+		v.Kind = Infdev
+	case strings.HasPrefix(s, "a1."):
+		v.Kind = Alpha
+	case strings.HasPrefix(s, "b1."):
+		v.Kind = Beta
+	default:
+		return Version{Raw: s}, fmt.Errorf("mcversion: unknown version format %q", s)
+	}
+	for _, d := range digitsPattern.FindAllString(s, -1) {
+		n, _ := strconv.Atoi(d)
+		v.Numbers = append(v.Numbers, n)
+	}
+	return v, nil
+}
+
+func splitNumbers(s string) []int {
+	parts := strings.Split(s, ".")
+	numbers := make([]int, len(parts))
+	for i, p := range parts {
+		numbers[i], _ = strconv.Atoi(p)
+	}
+	return numbers
+}
+
+// isWeekly reports whether the version is a weekly snapshot such as 24w14a.
+func (v Version) isWeekly() bool {
+	return v.Kind == Snapshot && v.Numbers == nil
+}
+
+// isModern reports whether the version belongs to the 1.x era of releases and snapshots.
+func (v Version) isModern() bool {
+	return v.Kind >= Snapshot
+}
+
+// Compare compares two parsed versions offline. It returns -1 if a is older
+// than b, 1 if a is newer and 0 if they are equal.
+//
+// Snapshots are placed relative to releases using a built-in table of release
+// dates, so the result for snapshots of unreleased versions is approximate.
+// Every version is mapped to a sort key first, so the order is transitive
+// even for mixed snapshots and releases.
+func (a Version) Compare(b Version) int {
+	return a.key().compare(b.key())
+}
+
+// sortKey is the position of a version in the offline order. Keys are compared
+// field by field, which keeps the order total.
+type sortKey struct {
+	// tier is 0 for unknown formats, the Kind for versions before 1.0 and
+	// Snapshot for everything from the 1.x era.
+	tier int
+	// year and week place 1.x era versions in time: the ISO week its release
+	// cycle started for a numbered version, the week of a weekly snapshot.
+	year, week int
+	// weekly sorts weekly snapshots after the numbered versions of the same week.
+	weekly  bool
+	numbers []int
+	kind    int
+	pre     int
+	suffix  string
+	raw     string
+}
+
+func (v Version) key() sortKey {
+	k := sortKey{numbers: v.Numbers, kind: int(v.Kind), pre: v.Pre, suffix: v.Suffix, raw: v.Raw}
+	switch {
+	case v.Kind == Unknown:
+	case !v.isModern():
+		k.tier = int(v.Kind)
+	case v.isWeekly():
+		k.tier = int(Snapshot)
+		k.year, k.week, k.weekly = 2000+v.Year, v.Week, true
+	default:
+		k.tier = int(Snapshot)
+		if date, ok := releaseDate(v.Numbers); ok {
+			k.year, k.week = date.ISOWeek()
+		} else {
+			// Versions of a later line are newer than every weekly snapshot.
+			k.year = math.MaxInt
+		}
+	}
+	return k
+}
+
+func (a sortKey) compare(b sortKey) int {
+	if c := cmpInt(a.tier, b.tier); c != 0 {
+		return c
+	}
+	switch {
+	case a.tier == int(Unknown):
+		return strings.Compare(a.raw, b.raw)
+	case a.tier != int(Snapshot):
+		if c := slices.Compare(a.numbers, b.numbers); c != 0 {
+			return c
+		}
+		return strings.Compare(a.raw, b.raw)
+	}
+	if c := cmpInt(a.year, b.year); c != 0 {
+		return c
+	}
+	if c := cmpInt(a.week, b.week); c != 0 {
+		return c
+	}
+	if a.weekly != b.weekly {
+		if a.weekly {
+			return 1
+		}
+		return -1
+	}
+	if a.weekly {
+		return strings.Compare(a.suffix, b.suffix)
+	}
+	if c := compareNumbers(a.numbers, b.numbers); c != 0 {
+		return c
+	}
+	if c := cmpInt(a.kind, b.kind); c != 0 {
+		return c
+	}
+	return cmpInt(a.pre, b.pre)
+}
+
+// compareNumbers compares numeric components, treating missing components as zero.
+func compareNumbers(a, b []int) int {
+	for i := range max(len(a), len(b)) {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := cmpInt(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Compare compares two Minecraft versions offline, see Version.Compare.
+func Compare(a, b string) int {
+	va, _ := Parse(a)
+	vb, _ := Parse(b)
+	return va.Compare(vb)
+}
+
+type knownRelease struct {
+	numbers []int
+	date    time.Time
+}
+
+// knownReleases lists the release dates of versions that started a new snapshot cycle.
+var knownReleases = func() []knownRelease {
+	table := []struct{ version, date string }{
+		{"1.0", "2011-11-18"},
+		{"1.1", "2012-01-12"},
+		{"1.2.1", "2012-03-01"},
+		{"1.3.1", "2012-08-01"},
+		{"1.4.2", "2012-10-25"},
+		{"1.5", "2013-03-13"},
+		{"1.6.1", "2013-07-01"},
+		{"1.7.2", "2013-10-25"},
+		{"1.8", "2014-09-02"},
+		{"1.9", "2016-02-29"},
+		{"1.10", "2016-06-08"},
+		{"1.11", "2016-11-14"},
+		{"1.12", "2017-06-07"},
+		{"1.13", "2018-07-18"},
+		{"1.14", "2019-04-23"},
+		{"1.15", "2019-12-10"},
+		{"1.16", "2020-06-23"},
+		{"1.17", "2021-06-08"},
+		{"1.18", "2021-11-30"},
+		{"1.19", "2022-06-07"},
+		{"1.19.3", "2022-12-07"},
+		{"1.19.4", "2023-03-14"},
+		{"1.20", "2023-06-07"},
+		{"1.20.2", "2023-09-21"},
+		{"1.20.3", "2023-12-05"},
+		{"1.20.5", "2024-04-23"},
+		{"1.21", "2024-06-13"},
+		{"1.21.2", "2024-10-22"},
+		{"1.21.4", "2024-12-03"},
+		{"1.21.5", "2025-03-25"},
+		{"1.21.6", "2025-06-17"},
+		{"1.21.9", "2025-09-30"},
+	}
+	releases := make([]knownRelease, len(table))
+	for i, r := range table {
+		date, err := time.Parse(time.DateOnly, r.date)
+		if err != nil {
+			panic(err)
+		}
+		releases[i] = knownRelease{numbers: splitNumbers(r.version), date: date}
+	}
+	return releases
+}()
+
+// releaseDate returns the release date of the newest known release cycle that
+// is not newer than the version. It reports false for versions of a later
+// major line than every known release cycle.
+func releaseDate(numbers []int) (time.Time, bool) {
+	last := knownReleases[len(knownReleases)-1]
+	if compareNumbers(numbers, last.numbers) > 0 {
+		if len(numbers) < 2 || numbers[0] != last.numbers[0] || numbers[1] != last.numbers[1] {
+			return time.Time{}, false
+		}
+		return last.date, true
+	}
+	date := knownReleases[0].date
+	for _, r := range knownReleases {
+		if compareNumbers(r.numbers, numbers) > 0 {
+			break
+		}
+		date = r.date
+	}
+	return date, true
+}
@@ -0,0 +1,219 @@
+package mcversion_test
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth/mcversion"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.20.1", "1.20.1", 0},
+		{"1.20", "1.20.0", 0},
+		{"1.20.1", "1.20.4", -1},
+		{"1.21", "1.20.6", 1},
+		{"1.21-pre3", "1.21", -1},
+		{"1.21-pre3", "1.21-rc1", -1},
+		{"1.21-rc1", "1.21-rc2", -1},
+		{"1.21-pre1", "1.20.6", 1},
+		{"1.14 Pre-Release 2", "1.14-pre1", 1},
+		{"24w14a", "24w14b", -1},
+		{"24w14a", "23w51a", 1},
+		{"24w14a", "1.20.4", 1},
+		{"24w14a", "1.20.5", -1},
+		{"24w14a", "1.20.5-pre1", -1},
+		{"23w31a", "1.20.1", 1},
+		{"25w41a", "1.21.10", 1},
+		{"26.1-snapshot-1", "26.1-pre1", -1},
+		{"26.1-snapshot-1", "25w45a", 1},
+		{"26.1", "1.21.10", 1},
+		{"b1.7.3", "1.0", -1},
+		{"a1.2.6", "b1.0", -1},
+		{"rd-132211", "c0.30_01c", -1},
+		{"not a version", "rd-132211", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if got := mcversion.Compare(tt.a, tt.b); got != tt.expected {
+				t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+			}
+			if got := mcversion.Compare(tt.b, tt.a); got != -tt.expected {
+				t.Errorf("Compare(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.expected)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected mcversion.Version
+		wantErr  bool
+	}{
+		{
+			version:  "1.20.1",
+			expected: mcversion.Version{Raw: "1.20.1", Kind: mcversion.Release, Numbers: []int{1, 20, 1}},
+		},
+		{
+			version:  "1.21-rc1",
+			expected: mcversion.Version{Raw: "1.21-rc1", Kind: mcversion.ReleaseCandidate, Numbers: []int{1, 21}, Pre: 1},
+		},
+		{
+			version:  "24w14a",
+			expected: mcversion.Version{Raw: "24w14a", Kind: mcversion.Snapshot, Year: 24, Week: 14, Suffix: "a"},
+		},
+		{
+			version:  "3D Shareware v1.34",
+			expected: mcversion.Version{Raw: "3D Shareware v1.34"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			result, err := mcversion.Parse(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Parse() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOrdering(t *testing.T) {
+	tags := []modrinth.GameVersion{
+		{Version: "1.20.5", VersionType: "release", Date: "2024-04-23T12:00:00Z"},
+		{Version: "24w14a", VersionType: "snapshot", Date: "2024-04-03T12:00:00Z"},
+		{Version: "1.20.4", VersionType: "release", Date: "2023-12-07T12:00:00Z"},
+		{Version: "1.20.3", VersionType: "release", Date: "2023-12-05T12:00:00Z"},
+	}
+	ordering := mcversion.NewOrdering(tags)
+
+	versions := []string{"1.20.5", "1.20.3", "1.19.2", "24w14a", "1.20.4"}
+	ordering.Sort(versions)
+	expected := []string{"1.19.2", "1.20.3", "1.20.4", "24w14a", "1.20.5"}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Errorf("Sort() = %v, want %v", versions, expected)
+	}
+	if got := ordering.Latest([]string{"1.20.3", "24w14a"}); got != "24w14a" {
+		t.Errorf("Latest() = %s, want 24w14a", got)
+	}
+	if got := ordering.VersionType("24w14a"); got != "snapshot" {
+		t.Errorf("VersionType() = %s, want snapshot", got)
+	}
+	if got := ordering.VersionType("1.19.2"); got != "release" {
+		t.Errorf("VersionType() = %s, want release", got)
+	}
+}
+
+func TestOrderingIsTotal(t *testing.T) {
+	tags := []modrinth.GameVersion{
+		{Version: "1.20.5", VersionType: "release", Date: "2024-04-23T12:00:00Z"},
+		{Version: "24w14a", VersionType: "snapshot", Date: "2024-04-03T12:00:00Z"},
+		{Version: "1.20.4", VersionType: "release", Date: "2023-12-07T12:00:00Z"},
+		{Version: "1.20.3", VersionType: "release", Date: "2023-12-05T12:00:00Z"},
+		{Version: "1.9.3", VersionType: "release", Date: "2016-05-10T12:00:00Z"},
+		// Unknown offline, so it is older than every other version without the tags
+		{Version: "1.RV-Pre1", VersionType: "snapshot", Date: "2016-03-31T12:00:00Z"},
+		{Version: "1.9.2", VersionType: "release", Date: "2016-03-30T12:00:00Z"},
+	}
+	versions := []string{
+		"1.20.5", "24w14a", "1.20.4", "1.20.3", "1.19.2", "23w51a", "24w10a",
+		"1.20.5-pre1", "1.21", "24w20a", "b1.7.3", "not a version",
+		"1.9.3", "1.RV-Pre1", "1.9.2", "1.8.9", "1.9.4", "16w02a",
+	}
+	for _, ordering := range []*mcversion.Ordering{nil, mcversion.NewOrdering(tags)} {
+		var expected []string
+		rng := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			shuffled := slices.Clone(versions)
+			rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+			sort.Slice(shuffled, func(i, j int) bool { return ordering.Compare(shuffled[i], shuffled[j]) < 0 })
+			if expected == nil {
+				expected = shuffled
+			} else if !reflect.DeepEqual(shuffled, expected) {
+				t.Fatalf("sort result depends on the input order: %v and %v", expected, shuffled)
+			}
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		rng      string
+		version  string
+		expected bool
+	}{
+		{">=1.20 <1.21", "1.20", true},
+		{">=1.20 <1.21", "1.20.6", true},
+		{">=1.20 <1.21", "1.21-pre1", true},
+		{">=1.20 <1.21", "1.21", false},
+		{">=1.20 <1.21", "1.19.4", false},
+		{"1.20.1 || 1.20.4", "1.20.4", true},
+		{"1.20.1 || 1.20.4", "1.20.2", false},
+		{"!=1.20.1", "1.20.2", true},
+		{"*", "24w14a", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rng+" "+tt.version, func(t *testing.T) {
+			r, err := mcversion.ParseRange(tt.rng)
+			if err != nil {
+				t.Fatalf("ParseRange() error = %v", err)
+			}
+			if got := r.Match(tt.version); got != tt.expected {
+				t.Errorf("Match() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := mcversion.ParseRange(">= || <1.20"); err == nil {
+		t.Errorf("ParseRange() error = nil, want error")
+	}
+}
+
+func TestCompareMod(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.4", -1},
+		{"1.2", "1.2.0", 0},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"0.92.2+1.20.1", "0.92.2+1.20.4", 0},
+		{"0.92.2+1.20.1", "0.92.10+1.20.1", -1},
+		{"mc1.20.1-2.3.4", "mc1.20.1-2.3.10", -1},
+		{"1.2.3.4", "1.2.3.5", -1},
+		{"1.2.3.4", "1.2.3", 1},
+		{"2.0-beta1", "2.0-rc1", -1},
+		{"2.0-rc1", "2.0", -1},
+		{"1.2.3.4-final", "1.2.3.4", 0},
+		{"1.2.3.4", "1.2.3.4-sp1", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if got := mcversion.CompareMod(tt.a, tt.b); got != tt.expected {
+				t.Errorf("CompareMod(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+			}
+			if got := mcversion.CompareMod(tt.b, tt.a); got != -tt.expected {
+				t.Errorf("CompareMod(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.expected)
+			}
+		})
+	}
+}
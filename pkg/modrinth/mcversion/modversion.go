@@ -0,0 +1,205 @@
+package mcversion
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CompareMod compares two mod version numbers, such as ProjectVersion.VersionNumber.
+// It returns -1 if a is older than b, 1 if a is newer and 0 if they are equal.
+//
+// Versions that both follow semantic versioning (with an optional "v" prefix
+// and up to three numeric components) are compared by semver precedence, so
+// build metadata like "+1.20.1" is ignored. Other versions are compared with
+// Maven's rules, where qualifiers such as alpha, beta and rc sort before the
+// release and numbers sort after qualifiers.
+func CompareMod(a, b string) int {
+	sa, okA := parseSemver(a)
+	sb, okB := parseSemver(b)
+	if okA && okB {
+		return sa.compare(sb)
+	}
+	return compareMaven(a, b)
+}
+
+type semver struct {
+	core [3]int
+	pre  []string
+}
+
+func parseSemver(s string) (semver, bool) {
+	var v semver
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		pre := s[i+1:]
+		s = s[:i]
+		if pre == "" {
+			return v, false
+		}
+		v.pre = strings.Split(pre, ".")
+		for _, id := range v.pre {
+			if id == "" {
+				return v, false
+			}
+		}
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || strings.HasPrefix(p, "+") {
+			return v, false
+		}
+		v.core[i] = n
+	}
+	return v, true
+}
+
+func (a semver) compare(b semver) int {
+	for i := range a.core {
+		if c := cmpInt(a.core[i], b.core[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a.pre) == 0 && len(b.pre) == 0:
+		return 0
+	case len(a.pre) == 0:
+		return 1
+	case len(b.pre) == 0:
+		return -1
+	}
+	for i := range min(len(a.pre), len(b.pre)) {
+		x, errX := strconv.Atoi(a.pre[i])
+		y, errY := strconv.Atoi(b.pre[i])
+		var c int
+		switch {
+		case errX == nil && errY == nil:
+			c = cmpInt(x, y)
+		case errX == nil:
+			c = -1
+		case errY == nil:
+			c = 1
+		default:
+			c = strings.Compare(a.pre[i], b.pre[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmpInt(len(a.pre), len(b.pre))
+}
+
+// mavenItem is a token of a Maven version, either a number or a qualifier.
+type mavenItem struct {
+	number    int
+	qualifier string
+	isNumber  bool
+}
+
+var qualifierRanks = map[string]int{
+	"alpha":     0,
+	"beta":      1,
+	"milestone": 2,
+	"rc":        3,
+	"snapshot":  4,
+	"":          5,
+	"sp":        6,
+}
+
+var qualifierAliases = map[string]string{
+	"a":       "alpha",
+	"b":       "beta",
+	"m":       "milestone",
+	"pre":     "rc",
+	"cr":      "rc",
+	"ga":      "",
+	"final":   "",
+	"release": "",
+}
+
+func parseMaven(s string) []mavenItem {
+	s = strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V"))
+	var items []mavenItem
+	var token strings.Builder
+	flush := func() {
+		if token.Len() == 0 {
+			return
+		}
+		t := token.String()
+		token.Reset()
+		if n, err := strconv.Atoi(t); err == nil {
+			items = append(items, mavenItem{number: n, isNumber: true})
+			return
+		}
+		if alias, ok := qualifierAliases[t]; ok {
+			t = alias
+		}
+		items = append(items, mavenItem{qualifier: t})
+	}
+	var prevDigit bool
+	for i, r := range s {
+		if r == '.' || r == '-' || r == '_' || r == '+' {
+			flush()
+			continue
+		}
+		digit := unicode.IsDigit(r)
+		if i > 0 && token.Len() > 0 && digit != prevDigit {
+			flush()
+		}
+		token.WriteRune(r)
+		prevDigit = digit
+	}
+	flush()
+	return items
+}
+
+func (a mavenItem) compare(b mavenItem) int {
+	switch {
+	case a.isNumber && b.isNumber:
+		return cmpInt(a.number, b.number)
+	case a.isNumber:
+		return 1
+	case b.isNumber:
+		return -1
+	}
+	ra, knownA := qualifierRanks[a.qualifier]
+	rb, knownB := qualifierRanks[b.qualifier]
+	switch {
+	case knownA && knownB:
+		return cmpInt(ra, rb)
+	case knownA:
+		return -1
+	case knownB:
+		return 1
+	}
+	return strings.Compare(a.qualifier, b.qualifier)
+}
+
+func compareMaven(a, b string) int {
+	ia := parseMaven(a)
+	ib := parseMaven(b)
+	for i := range max(len(ia), len(ib)) {
+		var x, y mavenItem
+		if i < len(ia) {
+			x = ia[i]
+		} else if i < len(ib) && ib[i].isNumber {
+			x = mavenItem{isNumber: true}
+		}
+		if i < len(ib) {
+			y = ib[i]
+		} else if i < len(ia) && ia[i].isNumber {
+			y = mavenItem{isNumber: true}
+		}
+		if c := x.compare(y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,171 @@
+package mcversion
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+// Ordering orders game versions by the Modrinth game version tags.
+// Versions that are not in the tags are compared offline.
+type Ordering struct {
+	index map[string]int
+	types map[string]string
+	// known holds the offline keys of the tagged versions in offline order,
+	// and oldest the lowest tag index among known[i:]. They place versions
+	// without a tag before the oldest tagged version that is newer offline.
+	known  []sortKey
+	oldest []int
+}
+
+// NewOrdering creates an ordering from game version tags. Tags are ordered by
+// their date; tags with the same or an invalid date keep the API order, which
+// lists the newest versions first.
+func NewOrdering(tags []modrinth.GameVersion) *Ordering {
+	type entry struct {
+		version string
+		date    time.Time
+		pos     int
+	}
+	entries := make([]entry, len(tags))
+	for i, tag := range tags {
+		date, _ := time.Parse(time.RFC3339, tag.Date)
+		entries[i] = entry{version: tag.Version, date: date, pos: i}
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		if c := a.date.Compare(b.date); c != 0 && !a.date.IsZero() && !b.date.IsZero() {
+			return c
+		}
+		return cmpInt(b.pos, a.pos)
+	})
+	o := &Ordering{
+		index: make(map[string]int, len(entries)),
+		types: make(map[string]string, len(tags)),
+	}
+	for i, e := range entries {
+		o.index[e.version] = i
+	}
+	for _, tag := range tags {
+		o.types[tag.Version] = tag.VersionType
+	}
+
+	order := make([]int, len(entries))
+	o.known = make([]sortKey, len(entries))
+	for i, e := range entries {
+		v, _ := Parse(e.version)
+		o.known[i] = v.key()
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int { return o.known[i].compare(o.known[j]) })
+	sorted := make([]sortKey, len(order))
+	o.oldest = make([]int, len(order))
+	for i := len(order) - 1; i >= 0; i-- {
+		sorted[i] = o.known[order[i]]
+		o.oldest[i] = order[i]
+		if i+1 < len(order) {
+			o.oldest[i] = min(o.oldest[i], o.oldest[i+1])
+		}
+	}
+	o.known = sorted
+	return o
+}
+
+// FetchOrdering fetches the game version tags and creates an ordering from them.
+func FetchOrdering(ctx context.Context, client *modrinth.ModrinthV2Client) (*Ordering, error) {
+	tags, err := client.GetGameVersionTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewOrdering(tags), nil
+}
+
+// Compare compares two game versions. It returns -1 if a is older than b,
+// 1 if a is newer and 0 if they are equal. A nil ordering compares offline.
+//
+// Tagged versions follow the tags. A version without a tag is placed right
+// before the oldest tagged version that is newer than it offline, so the
+// order stays transitive when tagged and untagged versions are mixed.
+func (o *Ordering) Compare(a, b string) int {
+	if o == nil {
+		return Compare(a, b)
+	}
+	return o.key(a).compare(o.key(b))
+}
+
+// orderingKey is the position of a version in an Ordering.
+type orderingKey struct {
+	// slot is the tag index of the version, or of the tagged version it precedes.
+	slot    int
+	tagged  bool
+	offline sortKey
+}
+
+func (o *Ordering) key(version string) orderingKey {
+	if i, ok := o.index[version]; ok {
+		return orderingKey{slot: i, tagged: true}
+	}
+	v, _ := Parse(version)
+	k := orderingKey{slot: len(o.known), offline: v.key()}
+	// The number of tagged versions that are not newer offline
+	n, _ := slices.BinarySearchFunc(o.known, k.offline, func(known, target sortKey) int {
+		if known.compare(target) <= 0 {
+			return -1
+		}
+		return 1
+	})
+	if n < len(o.known) {
+		k.slot = o.oldest[n]
+	}
+	return k
+}
+
+func (a orderingKey) compare(b orderingKey) int {
+	if c := cmpInt(a.slot, b.slot); c != 0 {
+		return c
+	}
+	// A tagged version comes after the untagged versions that precede it
+	if a.tagged != b.tagged {
+		if a.tagged {
+			return 1
+		}
+		return -1
+	}
+	return a.offline.compare(b.offline)
+}
+
+// VersionType returns the Modrinth version type of a game version, such as
+// release or snapshot. Without a tag, it is derived from the version format.
+func (o *Ordering) VersionType(version string) string {
+	if o != nil {
+		if t, ok := o.types[version]; ok {
+			return t
+		}
+	}
+	v, _ := Parse(version)
+	switch v.Kind {
+	case Release:
+		return "release"
+	case Snapshot, PreRelease, ReleaseCandidate:
+		return "snapshot"
+	case Alpha, Infdev, Classic, PreClassic:
+		return "alpha"
+	case Beta:
+		return "beta"
+	}
+	return ""
+}
+
+// Sort sorts game versions from oldest to newest.
+func (o *Ordering) Sort(versions []string) {
+	slices.SortStableFunc(versions, o.Compare)
+}
+
+// Latest returns the newest of the game versions, or an empty string if there are none.
+func (o *Ordering) Latest(versions []string) string {
+	if len(versions) == 0 {
+		return ""
+	}
+	return slices.MaxFunc(versions, o.Compare)
+}
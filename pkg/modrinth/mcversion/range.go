@@ -0,0 +1,106 @@
+package mcversion
+
+import (
+	"fmt"
+	"strings"
+)
+
+type constraint struct {
+	op      string
+	version string
+}
+
+func (c constraint) match(version string, compare func(a, b string) int) bool {
+	if c.op == "*" {
+		return true
+	}
+	r := compare(version, c.version)
+	switch c.op {
+	case ">=":
+		return r >= 0
+	case "<=":
+		return r <= 0
+	case ">":
+		return r > 0
+	case "<":
+		return r < 0
+	case "!=":
+		return r != 0
+	}
+	return r == 0
+}
+
+// Range is a set of version constraints, such as ">=1.20 <1.21".
+// Constraints separated by spaces must all match; alternatives are separated by "||".
+type Range struct {
+	raw  string
+	sets [][]constraint
+}
+
+// ParseRange parses a version range. Supported operators are >=, <=, >, <, =
+// and !=; a version without an operator must match exactly and "*" matches
+// any version.
+func ParseRange(s string) (*Range, error) {
+	r := &Range{raw: s}
+	for _, alt := range strings.Split(s, "||") {
+		var set []constraint
+		for _, field := range strings.Fields(alt) {
+			c := constraint{op: "="}
+			for _, op := range []string{">=", "<=", "!=", ">", "<", "="} {
+				if strings.HasPrefix(field, op) {
+					c.op = op
+					field = field[len(op):]
+					break
+				}
+			}
+			if field == "*" && c.op == "=" {
+				c.op = "*"
+			} else if field == "" {
+				return nil, fmt.Errorf("mcversion: missing version after %q in range %q", c.op, s)
+			}
+			c.version = field
+			set = append(set, c)
+		}
+		if len(set) == 0 {
+			return nil, fmt.Errorf("mcversion: empty constraint in range %q", s)
+		}
+		r.sets = append(r.sets, set)
+	}
+	return r, nil
+}
+
+// MustParseRange is like ParseRange but panics if the range cannot be parsed.
+func MustParseRange(s string) *Range {
+	r, err := ParseRange(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (r *Range) String() string {
+	return r.raw
+}
+
+// Match reports whether the game version is in the range, comparing offline.
+func (r *Range) Match(version string) bool {
+	return r.MatchFunc(version, Compare)
+}
+
+// MatchFunc reports whether the version is in the range using the comparison
+// function, such as Ordering.Compare or CompareMod.
+func (r *Range) MatchFunc(version string, compare func(a, b string) int) bool {
+	for _, set := range r.sets {
+		ok := true
+		for _, c := range set {
+			if !c.match(version, compare) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
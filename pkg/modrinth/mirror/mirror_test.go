@@ -0,0 +1,279 @@
+package mirror_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth/mirror"
+)
+
+type upstream struct {
+	server          *httptest.Server
+	projects        map[string]modrinth.Project
+	versions        map[string]modrinth.ProjectVersion
+	files           map[string][]byte
+	versionRequests int32
+	// listRequests counts the filtered version list requests.
+	listRequests int32
+	fileRequests int32
+}
+
+func sha1Hex(b []byte) string {
+	h := sha1.Sum(b)
+	return hex.EncodeToString(h[:])
+}
+
+func newUpstream(t *testing.T) *upstream {
+	u := &upstream{
+		projects: map[string]modrinth.Project{},
+		versions: map[string]modrinth.ProjectVersion{},
+		files:    map[string][]byte{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v2/projects", func(w http.ResponseWriter, r *http.Request) {
+		var ids []string
+		json.Unmarshal([]byte(r.URL.Query().Get("ids")), &ids)
+		result := []modrinth.Project{}
+		for _, id := range ids {
+			for _, p := range u.projects {
+				if p.ID == id || p.Slug == id {
+					result = append(result, p)
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+	mux.HandleFunc("GET /v2/versions", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&u.versionRequests, 1)
+		var ids []string
+		json.Unmarshal([]byte(r.URL.Query().Get("ids")), &ids)
+		result := []modrinth.ProjectVersion{}
+		for _, id := range ids {
+			if v, ok := u.versions[id]; ok {
+				result = append(result, v)
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+	mux.HandleFunc("GET /v2/project/{id}/version", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&u.listRequests, 1)
+		var loaders, gameVersions []string
+		json.Unmarshal([]byte(r.URL.Query().Get("loaders")), &loaders)
+		json.Unmarshal([]byte(r.URL.Query().Get("game_versions")), &gameVersions)
+		result := []modrinth.ProjectVersion{}
+		for _, id := range u.projects[r.PathValue("id")].Versions {
+			v := u.versions[id]
+			if (len(loaders) == 0 || slices.ContainsFunc(v.Loaders, func(l string) bool { return slices.Contains(loaders, l) })) &&
+				(len(gameVersions) == 0 || slices.ContainsFunc(v.GameVersions, func(g string) bool { return slices.Contains(gameVersions, g) })) {
+				result = append(result, v)
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+	mux.HandleFunc("GET /v2/project/{id}/members", func(w http.ResponseWriter, r *http.Request) {
+		p := u.projects[r.PathValue("id")]
+		json.NewEncoder(w).Encode([]modrinth.TeamMember{{TeamID: p.Team, User: modrinth.User{ID: "author-" + p.ID}, Role: "Owner", Accepted: true}})
+	})
+	mux.HandleFunc("GET /v2/tag/{name}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("name") {
+		case "game_version":
+			w.Write([]byte(`[{"version":"1.20.1","version_type":"release","date":"2023-06-12T13:25:51Z","major":false}]`))
+		case "side_type":
+			w.Write([]byte(`["required","optional","unsupported","unknown"]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	})
+	mux.HandleFunc("GET /cdn/{sha1}", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&u.fileRequests, 1)
+		w.Write(u.files[r.PathValue("sha1")])
+	})
+	u.server = httptest.NewServer(mux)
+	t.Cleanup(u.server.Close)
+	return u
+}
+
+func (u *upstream) addVersion(projectID, id, date string, loaders []string, content string, deps ...modrinth.VersionDependency) {
+	sha := sha1Hex([]byte(content))
+	u.files[sha] = []byte(content)
+	u.versions[id] = modrinth.ProjectVersion{
+		ID:            id,
+		ProjectID:     projectID,
+		VersionNumber: id,
+		DatePublished: date,
+		VersionType:   "release",
+		Loaders:       loaders,
+		GameVersions:  []string{"1.20.1"},
+		Dependencies:  deps,
+		Files: []modrinth.VersionFile{{
+			Hashes:   map[string]string{"sha1": sha},
+			URL:      u.server.URL + "/cdn/" + sha,
+			Filename: id + ".jar",
+			Primary:  true,
+			Size:     len(content),
+		}},
+	}
+	p := u.projects[projectID]
+	p.Versions = append(p.Versions, id)
+	p.Updated = date
+	u.projects[projectID] = p
+}
+
+func TestSyncAndServe(t *testing.T) {
+	up := newUpstream(t)
+	up.projects["AAAA"] = modrinth.Project{ID: "AAAA", Slug: "mod-a", Title: "Mod A", Team: "team-a", Updated: "1"}
+	up.projects["BBBB"] = modrinth.Project{ID: "BBBB", Slug: "lib-b", Title: "Library B", Updated: "1"}
+	up.projects["CCCC"] = modrinth.Project{ID: "CCCC", Slug: "extra-c", Title: "Extra C", Updated: "1"}
+	up.addVersion("BBBB", "b1", "2023-01-01T00:00:00Z", []string{"fabric"}, "lib b 1")
+	up.addVersion("BBBB", "b2-forge", "2023-02-01T00:00:00Z", []string{"forge"}, "lib b forge")
+	up.addVersion("CCCC", "c1", "2023-01-01T00:00:00Z", []string{"fabric"}, "extra c 1")
+	up.addVersion("AAAA", "a1", "2023-03-01T00:00:00Z", []string{"fabric"}, "mod a 1",
+		modrinth.VersionDependency{ProjectID: "BBBB", DependencyType: "required"},
+		modrinth.VersionDependency{ProjectID: "CCCC", DependencyType: "optional"},
+	)
+
+	dir := t.TempDir()
+	upstreamClient := modrinth.NewModrinthV2Client(modrinth.WithBaseURL(up.server.URL))
+	syncer := mirror.NewSyncer(upstreamClient, dir, mirror.SyncOptions{Loaders: []string{"fabric"}})
+	report, err := syncer.Sync(context.Background(), []string{"mod-a", "missing"})
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if report.Projects != 2 || report.DownloadedFiles != 2 {
+		t.Errorf("Sync() report = %+v, want 2 projects and 2 files", report)
+	}
+	if !reflect.DeepEqual(report.SkippedProjects, []string{"missing"}) {
+		t.Errorf("Sync() skipped = %v, want [missing]", report.SkippedProjects)
+	}
+	if mirror.NewStore(dir).HasVersion("b2-forge") {
+		t.Errorf("Sync() stored b2-forge, which the loader filter rejects")
+	}
+
+	server, err := mirror.NewServer(dir)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	local := httptest.NewServer(server)
+	defer local.Close()
+	client := modrinth.NewModrinthV2Client(modrinth.WithBaseURL(local.URL))
+	ctx := context.Background()
+
+	project, err := client.GetProject(ctx, "lib-b")
+	if err != nil {
+		t.Fatalf("GetProject() error = %v", err)
+	}
+	if project.ID != "BBBB" || !reflect.DeepEqual(project.Versions, []string{"b1"}) {
+		t.Errorf("GetProject() = %+v, want BBBB with only the fabric version", project)
+	}
+	if _, err := client.GetProject(ctx, "extra-c"); err == nil {
+		t.Errorf("GetProject() of an optional dependency error = nil, want not found")
+	}
+
+	versions, err := client.GetProjectVersions(ctx, "mod-a", modrinth.GetProjectVersionsOptions{Loaders: []string{"fabric"}})
+	if err != nil || len(versions) != 1 || versions[0].ID != "a1" {
+		t.Fatalf("GetProjectVersions() = %v, %v, want [a1]", versions, err)
+	}
+
+	byHash, err := client.GetProjectVersionsByHash(ctx, []string{sha1Hex([]byte("lib b 1"))}, "sha1")
+	if err != nil || len(byHash) != 1 {
+		t.Fatalf("GetProjectVersionsByHash() = %v, %v, want one version", byHash, err)
+	}
+
+	resp, err := http.Get(versions[0].Files[0].URL)
+	if err != nil {
+		t.Fatalf("download error = %v", err)
+	}
+	content, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(content) != "mod a 1" {
+		t.Errorf("download = %q, want %q", content, "mod a 1")
+	}
+
+	members, err := client.GetProjectTeamMembers(ctx, "mod-a")
+	if err != nil || len(members) != 1 || members[0].User.ID != "author-AAAA" {
+		t.Errorf("GetProjectTeamMembers() = %v, %v, want author-AAAA", members, err)
+	}
+	resp, err = http.Get(local.URL + "/v2/team/team-a/members")
+	if err != nil {
+		t.Fatalf("team members error = %v", err)
+	}
+	json.NewDecoder(resp.Body).Decode(&members)
+	resp.Body.Close()
+	if len(members) != 1 || members[0].TeamID != "team-a" {
+		t.Errorf("team members = %v, want the members of mod-a", members)
+	}
+	statistics, err := client.GetStatistics(ctx)
+	if err != nil || statistics.Projects != 2 || statistics.Versions != 2 || statistics.Files != 2 || statistics.Authors != 2 {
+		t.Errorf("GetStatistics() = %+v, %v, want 2 projects, versions, files and authors", statistics, err)
+	}
+	random, err := client.GetRandomProjects(ctx, 5)
+	if err != nil || len(random) != 2 {
+		t.Errorf("GetRandomProjects() = %v, %v, want both projects", random, err)
+	}
+	// Routes that are not mirrored say so instead of claiming the resource is missing.
+	var apiErr *modrinth.ModrinthAPIError
+	if _, err := client.GetUser(ctx, "author-AAAA"); !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotImplemented {
+		t.Errorf("GetUser() error = %v, want 501", err)
+	}
+
+	tags, err := client.GetGameVersionTags(ctx)
+	if err != nil || len(tags) != 1 {
+		t.Errorf("GetGameVersionTags() = %v, %v, want one tag", tags, err)
+	}
+	sides, err := client.GetSideTypeTags(ctx)
+	if err != nil || len(sides) != 4 {
+		t.Errorf("GetSideTypeTags() = %v, %v, want four tags", sides, err)
+	}
+	if _, err := client.GetDonationPlatformTags(ctx); err != nil {
+		t.Errorf("GetDonationPlatformTags() error = %v", err)
+	}
+
+	// A second sync only fetches the new versions of the updated project.
+	atomic.StoreInt32(&up.versionRequests, 0)
+	atomic.StoreInt32(&up.listRequests, 0)
+	atomic.StoreInt32(&up.fileRequests, 0)
+	up.addVersion("AAAA", "a2", "2023-04-01T00:00:00Z", []string{"fabric"}, "mod a 2")
+	up.addVersion("AAAA", "a2-forge", "2023-04-01T00:00:00Z", []string{"forge"}, "mod a 2 forge")
+	report, err = syncer.Sync(context.Background(), []string{"mod-a"})
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if report.FetchedVersions != 2 || up.fileRequests != 1 || up.listRequests != 0 {
+		t.Errorf("incremental Sync() fetched %d versions, %d files and %d version lists, want 2, 1 and 0",
+			report.FetchedVersions, up.fileRequests, up.listRequests)
+	}
+	if report.Projects != 2 || report.UnchangedProjects != 1 {
+		t.Errorf("incremental Sync() report = %+v, want lib-b unchanged", report)
+	}
+	if mirror.NewStore(dir).HasVersion("a2-forge") {
+		t.Errorf("incremental Sync() stored a2-forge, which the loader filter rejects")
+	}
+
+	// Nothing changed, so nothing is fetched.
+	atomic.StoreInt32(&up.versionRequests, 0)
+	report, err = syncer.Sync(context.Background(), []string{"mod-a"})
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if report.UnchangedProjects != 2 || report.FetchedVersions != 0 || up.versionRequests != 0 || up.listRequests != 0 {
+		t.Errorf("unchanged Sync() report = %+v after %d version requests, want nothing fetched", report, up.versionRequests)
+	}
+	if err := server.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	latest, err := client.GetLatestProjectVersion(ctx, sha1Hex([]byte("mod a 1")), "sha1", []string{"fabric"}, []string{"1.20.1"})
+	if err != nil || latest.ID != "a2" {
+		t.Errorf("GetLatestProjectVersion() = %v, %v, want a2", latest, err)
+	}
+}
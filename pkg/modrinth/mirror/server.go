@@ -0,0 +1,624 @@
+package mirror
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+// Server serves a mirror directory through the Modrinth /v2 routes, so a
+// client created with modrinth.WithBaseURL pointing at it works offline.
+// File URLs in version responses are rewritten to point at the server.
+//
+// Search only matches the query against the title, slug and description of
+// mirrored projects; facets and filters are ignored. Statistics count the
+// mirrored content. Users, collections, follows and every route that changes
+// data are not mirrored and answer 501 Not Implemented.
+type Server struct {
+	// PublicURL is the base URL used for file links. When empty, it is derived from the request.
+	PublicURL string
+
+	store *Store
+	mux   *http.ServeMux
+	mu    sync.RWMutex
+	data  *snapshot
+}
+
+// snapshot is the in-memory view of the mirror directory.
+type snapshot struct {
+	projects        map[string]*modrinth.Project
+	projectIDs      []string
+	versions        map[string]*modrinth.ProjectVersion
+	projectVersions map[string][]*modrinth.ProjectVersion
+	hashes          map[string]map[string]*modrinth.ProjectVersion
+	tags            map[string]json.RawMessage
+	// members holds the team members by project ID and teams the project IDs by team ID.
+	members    map[string][]modrinth.TeamMember
+	teams      map[string]string
+	statistics modrinth.Statistics
+}
+
+// NewServer creates a server for the mirror directory and loads its content.
+func NewServer(dir string) (*Server, error) {
+	s := &Server{store: NewStore(dir), mux: http.NewServeMux()}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	s.mux.HandleFunc("GET /v2/project/{id}", s.handleProject)
+	s.mux.HandleFunc("GET /v2/project/{id}/check", s.handleCheck)
+	s.mux.HandleFunc("GET /v2/project/{id}/dependencies", s.handleDependencies)
+	s.mux.HandleFunc("GET /v2/project/{id}/members", s.handleProjectMembers)
+	s.mux.HandleFunc("GET /v2/project/{id}/version", s.handleProjectVersions)
+	s.mux.HandleFunc("GET /v2/project/{id}/version/{version}", s.handleProjectVersion)
+	s.mux.HandleFunc("GET /v2/projects", s.handleProjects)
+	s.mux.HandleFunc("GET /v2/projects_random", s.handleRandomProjects)
+	s.mux.HandleFunc("GET /v2/search", s.handleSearch)
+	s.mux.HandleFunc("GET /v2/version/{id}", s.handleVersion)
+	s.mux.HandleFunc("GET /v2/versions", s.handleVersions)
+	s.mux.HandleFunc("GET /v2/version_file/{hash}", s.handleVersionFile)
+	s.mux.HandleFunc("POST /v2/version_files", s.handleVersionFiles)
+	s.mux.HandleFunc("POST /v2/version_file/{hash}/update", s.handleVersionFileUpdate)
+	s.mux.HandleFunc("POST /v2/version_files/update", s.handleVersionFilesUpdate)
+	s.mux.HandleFunc("GET /v2/tag/{name}", s.handleTag)
+	s.mux.HandleFunc("GET /v2/team/{id}/members", s.handleTeamMembers)
+	s.mux.HandleFunc("GET /v2/statistics", s.handleStatistics)
+	s.mux.HandleFunc("/v2/", notImplemented)
+	s.mux.HandleFunc("GET /files/{sha1}/{filename}", s.handleFile)
+	return s, nil
+}
+
+// Reload reads the mirror directory again, e.g. after a sync.
+func (s *Server) Reload() error {
+	index, err := s.store.LoadIndex()
+	if err != nil {
+		return err
+	}
+	data := &snapshot{
+		projects:        make(map[string]*modrinth.Project),
+		versions:        make(map[string]*modrinth.ProjectVersion),
+		projectVersions: make(map[string][]*modrinth.ProjectVersion),
+		hashes:          make(map[string]map[string]*modrinth.ProjectVersion),
+		tags:            make(map[string]json.RawMessage),
+		members:         make(map[string][]modrinth.TeamMember),
+		teams:           make(map[string]string),
+	}
+	files := make(map[string]bool)
+	authors := make(map[string]bool)
+	for id, state := range index.Projects {
+		project, err := s.store.LoadProject(id)
+		if err != nil {
+			return err
+		}
+		var versions []*modrinth.ProjectVersion
+		for _, vid := range state.Versions {
+			v, err := s.store.LoadVersion(vid)
+			if err != nil {
+				return err
+			}
+			versions = append(versions, v)
+			data.versions[v.ID] = v
+			for _, f := range v.Files {
+				files[f.Hashes["sha1"]] = true
+				for algorithm, hash := range f.Hashes {
+					if data.hashes[algorithm] == nil {
+						data.hashes[algorithm] = make(map[string]*modrinth.ProjectVersion)
+					}
+					data.hashes[algorithm][hash] = v
+				}
+			}
+		}
+		slices.SortStableFunc(versions, func(a, b *modrinth.ProjectVersion) int {
+			return cmp.Compare(b.DatePublished, a.DatePublished)
+		})
+		// Only advertise versions that are mirrored.
+		project.Versions = project.Versions[:0]
+		for _, v := range versions {
+			project.Versions = append(project.Versions, v.ID)
+		}
+		data.projects[project.ID] = project
+		if project.Slug != "" {
+			data.projects[project.Slug] = project
+		}
+		data.projectIDs = append(data.projectIDs, project.ID)
+		data.projectVersions[project.ID] = versions
+
+		// Mirrors synced before members were mirrored have none.
+		members, err := s.store.LoadMembers(project.ID)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		data.members[project.ID] = members
+		if project.Team != "" {
+			data.teams[project.Team] = project.ID
+		}
+		for _, m := range members {
+			authors[m.User.ID] = true
+		}
+	}
+	slices.Sort(data.projectIDs)
+	data.statistics = modrinth.Statistics{
+		Projects: len(data.projectIDs),
+		Versions: len(data.versions),
+		Files:    len(files),
+		Authors:  len(authors),
+	}
+	for _, name := range TagNames {
+		var raw json.RawMessage
+		if err := s.store.LoadTag(name, &raw); err == nil {
+			data.tags[name] = raw
+		}
+	}
+
+	s.mu.Lock()
+	s.data = data
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) snapshot() *snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data
+}
+
+func (s *Server) baseURL(r *http.Request) string {
+	if s.PublicURL != "" {
+		return strings.TrimSuffix(s.PublicURL, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// localVersion returns a copy of the version with file URLs pointing at the server.
+func (s *Server) localVersion(r *http.Request, v *modrinth.ProjectVersion, changelog bool) modrinth.ProjectVersion {
+	local := *v
+	local.Files = make([]modrinth.VersionFile, len(v.Files))
+	base := s.baseURL(r)
+	for i, f := range v.Files {
+		if sha := f.Hashes["sha1"]; sha != "" {
+			f.URL = base + "/files/" + sha + "/" + url.PathEscape(f.Filename)
+		}
+		local.Files[i] = f
+	}
+	if !changelog {
+		local.Changelog = ""
+	}
+	return local
+}
+
+func (s *Server) localVersions(r *http.Request, versions []*modrinth.ProjectVersion) []modrinth.ProjectVersion {
+	result := make([]modrinth.ProjectVersion, len(versions))
+	for i, v := range versions {
+		result[i] = s.localVersion(r, v, true)
+	}
+	return result
+}
+
+func (s *Server) handleProject(w http.ResponseWriter, r *http.Request) {
+	project, ok := s.snapshot().projects[r.PathValue("id")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	respond(w, project)
+}
+
+func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
+	project, ok := s.snapshot().projects[r.PathValue("id")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	respond(w, map[string]string{"id": project.ID})
+}
+
+func (s *Server) handleDependencies(w http.ResponseWriter, r *http.Request) {
+	data := s.snapshot()
+	project, ok := data.projects[r.PathValue("id")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	result := struct {
+		Projects []*modrinth.Project       `json:"projects"`
+		Versions []modrinth.ProjectVersion `json:"versions"`
+	}{Projects: []*modrinth.Project{}, Versions: []modrinth.ProjectVersion{}}
+	seenProjects := make(map[string]bool)
+	seenVersions := make(map[string]bool)
+	for _, v := range data.projectVersions[project.ID] {
+		for _, dep := range v.Dependencies {
+			if dep.ProjectID != "" && !seenProjects[dep.ProjectID] {
+				if p, ok := data.projects[dep.ProjectID]; ok {
+					seenProjects[dep.ProjectID] = true
+					result.Projects = append(result.Projects, p)
+				}
+			}
+			if dep.VersionID != "" && !seenVersions[dep.VersionID] {
+				if dv, ok := data.versions[dep.VersionID]; ok {
+					seenVersions[dep.VersionID] = true
+					result.Versions = append(result.Versions, s.localVersion(r, dv, true))
+				}
+			}
+		}
+	}
+	respond(w, result)
+}
+
+func (s *Server) handleProjectMembers(w http.ResponseWriter, r *http.Request) {
+	data := s.snapshot()
+	project, ok := data.projects[r.PathValue("id")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	respondMembers(w, data.members[project.ID])
+}
+
+func (s *Server) handleTeamMembers(w http.ResponseWriter, r *http.Request) {
+	data := s.snapshot()
+	projectID, ok := data.teams[r.PathValue("id")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	respondMembers(w, data.members[projectID])
+}
+
+func respondMembers(w http.ResponseWriter, members []modrinth.TeamMember) {
+	if members == nil {
+		members = []modrinth.TeamMember{}
+	}
+	respond(w, members)
+}
+
+func (s *Server) handleProjectVersions(w http.ResponseWriter, r *http.Request) {
+	data := s.snapshot()
+	project, ok := data.projects[r.PathValue("id")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	q := r.URL.Query()
+	var loaders, gameVersions []string
+	if err := parseJSONParam(q, "loaders", &loaders); err != nil {
+		badRequest(w, err)
+		return
+	}
+	if err := parseJSONParam(q, "game_versions", &gameVersions); err != nil {
+		badRequest(w, err)
+		return
+	}
+	changelog := q.Get("include_changelog") != "false"
+	result := []modrinth.ProjectVersion{}
+	for _, v := range data.projectVersions[project.ID] {
+		if !matchesAny(v.Loaders, loaders) || !matchesAny(v.GameVersions, gameVersions) {
+			continue
+		}
+		if featured := q.Get("featured"); featured != "" && strconv.FormatBool(v.Featured) != featured {
+			continue
+		}
+		result = append(result, s.localVersion(r, v, changelog))
+	}
+	respond(w, result)
+}
+
+func (s *Server) handleProjectVersion(w http.ResponseWriter, r *http.Request) {
+	data := s.snapshot()
+	project, ok := data.projects[r.PathValue("id")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	key := r.PathValue("version")
+	for _, v := range data.projectVersions[project.ID] {
+		if v.ID == key || v.VersionNumber == key {
+			respond(w, s.localVersion(r, v, true))
+			return
+		}
+	}
+	notFound(w)
+}
+
+func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
+	data := s.snapshot()
+	var ids []string
+	if err := parseJSONParam(r.URL.Query(), "ids", &ids); err != nil {
+		badRequest(w, err)
+		return
+	}
+	result := []*modrinth.Project{}
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if p, ok := data.projects[id]; ok && !seen[p.ID] {
+			seen[p.ID] = true
+			result = append(result, p)
+		}
+	}
+	respond(w, result)
+}
+
+func (s *Server) handleRandomProjects(w http.ResponseWriter, r *http.Request) {
+	data := s.snapshot()
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count < 0 || count > 100 {
+		respondError(w, http.StatusBadRequest, "invalid_input", "count must be between 0 and 100")
+		return
+	}
+	result := []*modrinth.Project{}
+	for _, i := range rand.Perm(len(data.projectIDs))[:min(count, len(data.projectIDs))] {
+		result = append(result, data.projects[data.projectIDs[i]])
+	}
+	respond(w, result)
+}
+
+func (s *Server) handleStatistics(w http.ResponseWriter, r *http.Request) {
+	respond(w, s.snapshot().statistics)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	data := s.snapshot()
+	q := r.URL.Query()
+	query := strings.ToLower(q.Get("query"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 10
+	}
+
+	var matches []*modrinth.Project
+	for _, id := range data.projectIDs {
+		p := data.projects[id]
+		if query == "" || strings.Contains(strings.ToLower(p.Title), query) ||
+			strings.Contains(strings.ToLower(p.Slug), query) ||
+			strings.Contains(strings.ToLower(p.Description), query) {
+			matches = append(matches, p)
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b *modrinth.Project) int {
+		return cmp.Compare(b.Downloads, a.Downloads)
+	})
+
+	result := modrinth.SearchResult{Hits: []modrinth.SearchResultHit{}, Offset: offset, Limit: limit, TotalHits: len(matches)}
+	for i := offset; i < len(matches) && i < offset+limit; i++ {
+		p := matches[i]
+		hit := modrinth.SearchResultHit{
+			Slug:               p.Slug,
+			ProjectID:          p.ID,
+			ProjectType:        p.ProjectType,
+			Title:              p.Title,
+			Description:        p.Description,
+			Categories:         p.Categories,
+			Downloads:          p.Downloads,
+			Follows:            p.Followers,
+			IconURL:            p.IconURL,
+			DateCreated:        p.Published,
+			DateModified:       p.Updated,
+			License:            p.License.ID,
+			ClientSide:         p.ClientSide,
+			ServerSide:         p.ServerSide,
+			MonetizationStatus: p.MonetizationStatus,
+		}
+		for _, v := range data.projectVersions[p.ID] {
+			for _, gv := range v.GameVersions {
+				if !slices.Contains(hit.Versions, gv) {
+					hit.Versions = append(hit.Versions, gv)
+				}
+			}
+		}
+		if len(p.Versions) > 0 {
+			hit.LatestVersion = p.Versions[0]
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	respond(w, result)
+}
+
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	v, ok := s.snapshot().versions[r.PathValue("id")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	respond(w, s.localVersion(r, v, true))
+}
+
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request) {
+	data := s.snapshot()
+	var ids []string
+	if err := parseJSONParam(r.URL.Query(), "ids", &ids); err != nil {
+		badRequest(w, err)
+		return
+	}
+	var versions []*modrinth.ProjectVersion
+	for _, id := range ids {
+		if v, ok := data.versions[id]; ok {
+			versions = append(versions, v)
+		}
+	}
+	respond(w, s.localVersions(r, versions))
+}
+
+func algorithmOf(algorithm string) string {
+	if algorithm == "" {
+		return "sha1"
+	}
+	return algorithm
+}
+
+func (s *Server) handleVersionFile(w http.ResponseWriter, r *http.Request) {
+	algorithm := algorithmOf(r.URL.Query().Get("algorithm"))
+	v, ok := s.snapshot().hashes[algorithm][r.PathValue("hash")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	respond(w, s.localVersion(r, v, true))
+}
+
+func (s *Server) handleVersionFiles(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Hashes    []string `json:"hashes"`
+		Algorithm string   `json:"algorithm"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		badRequest(w, err)
+		return
+	}
+	hashes := s.snapshot().hashes[algorithmOf(body.Algorithm)]
+	result := make(map[string]modrinth.ProjectVersion)
+	for _, h := range body.Hashes {
+		if v, ok := hashes[h]; ok {
+			result[h] = s.localVersion(r, v, true)
+		}
+	}
+	respond(w, result)
+}
+
+// latest returns the newest mirrored version of the project that matches the filters.
+func (data *snapshot) latest(projectID string, loaders, gameVersions []string) *modrinth.ProjectVersion {
+	var best *modrinth.ProjectVersion
+	for _, v := range data.projectVersions[projectID] {
+		if !matchesAny(v.Loaders, loaders) || !matchesAny(v.GameVersions, gameVersions) {
+			continue
+		}
+		if best == nil || publishedAfter(v, best) {
+			best = v
+		}
+	}
+	return best
+}
+
+func publishedAfter(a, b *modrinth.ProjectVersion) bool {
+	ta, errA := time.Parse(time.RFC3339, a.DatePublished)
+	tb, errB := time.Parse(time.RFC3339, b.DatePublished)
+	if errA != nil || errB != nil {
+		return a.DatePublished > b.DatePublished
+	}
+	return ta.After(tb)
+}
+
+func (s *Server) handleVersionFileUpdate(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Loaders      []string `json:"loaders"`
+		GameVersions []string `json:"game_versions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		badRequest(w, err)
+		return
+	}
+	data := s.snapshot()
+	algorithm := algorithmOf(r.URL.Query().Get("algorithm"))
+	current, ok := data.hashes[algorithm][r.PathValue("hash")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	latest := data.latest(current.ProjectID, body.Loaders, body.GameVersions)
+	if latest == nil {
+		notFound(w)
+		return
+	}
+	respond(w, s.localVersion(r, latest, true))
+}
+
+func (s *Server) handleVersionFilesUpdate(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Hashes       []string `json:"hashes"`
+		Algorithm    string   `json:"algorithm"`
+		Loaders      []string `json:"loaders"`
+		GameVersions []string `json:"game_versions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		badRequest(w, err)
+		return
+	}
+	data := s.snapshot()
+	hashes := data.hashes[algorithmOf(body.Algorithm)]
+	result := make(map[string]modrinth.ProjectVersion)
+	for _, h := range body.Hashes {
+		current, ok := hashes[h]
+		if !ok {
+			continue
+		}
+		if latest := data.latest(current.ProjectID, body.Loaders, body.GameVersions); latest != nil {
+			result[h] = s.localVersion(r, latest, true)
+		}
+	}
+	respond(w, result)
+}
+
+func (s *Server) handleTag(w http.ResponseWriter, r *http.Request) {
+	tag, ok := s.snapshot().tags[r.PathValue("name")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(tag)
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	sha := r.PathValue("sha1")
+	if len(sha) != 40 || strings.ContainsAny(sha, `/\.`) {
+		notFound(w)
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="`+strings.ReplaceAll(r.PathValue("filename"), `"`, "")+`"`)
+	http.ServeFile(w, r, s.store.FilePath(sha))
+}
+
+func matchesAny(values, filter []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(values, func(v string) bool { return slices.Contains(filter, v) })
+}
+
+func parseJSONParam(q url.Values, name string, result any) error {
+	raw := q.Get(name)
+	if raw == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(raw), result)
+}
+
+func respond(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
+
+func respondError(w http.ResponseWriter, status int, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": code, "description": description})
+}
+
+func notFound(w http.ResponseWriter) {
+	respondError(w, http.StatusNotFound, "not_found", "the requested resource is not mirrored")
+}
+
+func notImplemented(w http.ResponseWriter, r *http.Request) {
+	respondError(w, http.StatusNotImplemented, "not_implemented", "the mirror does not serve "+r.Method+" "+r.URL.Path)
+}
+
+func badRequest(w http.ResponseWriter, err error) {
+	respondError(w, http.StatusBadRequest, "invalid_input", err.Error())
+}
@@ -0,0 +1,207 @@
+// Package mirror keeps an offline copy of Modrinth projects in a local
+// directory and serves it through the same /v2 routes that the modrinth
+// client uses.
+//
+// The directory layout is:
+//
+//	index.json                 synced projects and their mirrored version IDs
+//	projects/{id}.json         project metadata
+//	members/{id}.json          team members of the project
+//	versions/{id}.json         version metadata with the original file URLs
+//	files/{sha1[:2]}/{sha1}    file contents addressed by SHA-1
+//	tags/{name}.json           the tags listed in TagNames
+package mirror
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+// TagNames are the /v2/tag endpoints that are synced and served.
+var TagNames = []string{
+	"game_version",
+	"loader",
+	"category",
+	"license",
+	"donation_platform",
+	"report_type",
+	"project_type",
+	"side_type",
+}
+
+// Index records which projects are mirrored and which of their versions are available.
+type Index struct {
+	Projects map[string]*ProjectState `json:"projects"`
+}
+
+// ProjectState is the sync state of a mirrored project.
+type ProjectState struct {
+	// Updated is the Project.Updated value of the last sync.
+	Updated string `json:"updated"`
+	// Versions are the IDs of the mirrored versions.
+	Versions []string `json:"versions"`
+	// Rejected are the IDs of the versions the filters rejected.
+	Rejected []string `json:"rejected,omitempty"`
+	// Filter records the SyncOptions filters Versions and Rejected were computed with.
+	Filter string `json:"filter,omitempty"`
+}
+
+// Store reads and writes the mirror directory.
+type Store struct {
+	Dir string
+}
+
+// NewStore creates a store for the directory.
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+func (s *Store) projectPath(id string) string {
+	return filepath.Join(s.Dir, "projects", id+".json")
+}
+
+func (s *Store) membersPath(projectID string) string {
+	return filepath.Join(s.Dir, "members", projectID+".json")
+}
+
+func (s *Store) versionPath(id string) string {
+	return filepath.Join(s.Dir, "versions", id+".json")
+}
+
+func (s *Store) tagPath(name string) string {
+	return filepath.Join(s.Dir, "tags", name+".json")
+}
+
+// FilePath returns the path of the file with the SHA-1 hash.
+func (s *Store) FilePath(sha1 string) string {
+	if len(sha1) < 2 {
+		return filepath.Join(s.Dir, "files", sha1)
+	}
+	return filepath.Join(s.Dir, "files", sha1[:2], sha1)
+}
+
+// HasFile reports whether the file with the SHA-1 hash is stored.
+func (s *Store) HasFile(sha1 string) bool {
+	_, err := os.Stat(s.FilePath(sha1))
+	return err == nil
+}
+
+// HasVersion reports whether the version metadata is stored.
+func (s *Store) HasVersion(id string) bool {
+	_, err := os.Stat(s.versionPath(id))
+	return err == nil
+}
+
+// LoadIndex reads the index. A missing index is returned empty.
+func (s *Store) LoadIndex() (*Index, error) {
+	index := &Index{}
+	err := readJSON(filepath.Join(s.Dir, "index.json"), index)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if index.Projects == nil {
+		index.Projects = make(map[string]*ProjectState)
+	}
+	return index, nil
+}
+
+// SaveIndex writes the index.
+func (s *Store) SaveIndex(index *Index) error {
+	return writeJSON(filepath.Join(s.Dir, "index.json"), index)
+}
+
+// LoadProject reads a project by ID.
+func (s *Store) LoadProject(id string) (*modrinth.Project, error) {
+	var project modrinth.Project
+	err := readJSON(s.projectPath(id), &project)
+	return &project, err
+}
+
+// SaveProject writes a project.
+func (s *Store) SaveProject(project *modrinth.Project) error {
+	return writeJSON(s.projectPath(project.ID), project)
+}
+
+// HasMembers reports whether the team members of the project are stored.
+func (s *Store) HasMembers(projectID string) bool {
+	_, err := os.Stat(s.membersPath(projectID))
+	return err == nil
+}
+
+// LoadMembers reads the team members of a project.
+func (s *Store) LoadMembers(projectID string) ([]modrinth.TeamMember, error) {
+	var members []modrinth.TeamMember
+	err := readJSON(s.membersPath(projectID), &members)
+	return members, err
+}
+
+// SaveMembers writes the team members of a project.
+func (s *Store) SaveMembers(projectID string, members []modrinth.TeamMember) error {
+	return writeJSON(s.membersPath(projectID), members)
+}
+
+// LoadVersion reads a version by ID.
+func (s *Store) LoadVersion(id string) (*modrinth.ProjectVersion, error) {
+	var version modrinth.ProjectVersion
+	err := readJSON(s.versionPath(id), &version)
+	return &version, err
+}
+
+// SaveVersion writes a version.
+func (s *Store) SaveVersion(version *modrinth.ProjectVersion) error {
+	return writeJSON(s.versionPath(version.ID), version)
+}
+
+// LoadTag reads a tag list into result.
+func (s *Store) LoadTag(name string, result any) error {
+	return readJSON(s.tagPath(name), result)
+}
+
+// SaveTag writes a tag list.
+func (s *Store) SaveTag(name string, tag any) error {
+	return writeJSON(s.tagPath(name), tag)
+}
+
+func readJSON(path string, result any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, result)
+}
+
+func writeJSON(path string, value any) error {
+	b, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, func(f *os.File) error {
+		_, err := f.Write(b)
+		return err
+	})
+}
+
+// writeFileAtomic writes a file through a temporary file so readers never see partial content.
+func writeFileAtomic(path string, write func(f *os.File) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,447 @@
+package mirror
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+// SyncOptions defines which versions are mirrored.
+type SyncOptions struct {
+	// Loaders keeps only versions supporting one of the loaders. Empty keeps all.
+	Loaders []string
+	// GameVersions keeps only versions supporting one of the game versions. Empty keeps all.
+	GameVersions []string
+	// IncludeOptional also mirrors optional dependencies.
+	IncludeOptional bool
+	// Concurrency is the maximum number of parallel downloads. Defaults to 4.
+	Concurrency int
+}
+
+// SyncReport summarizes a sync.
+type SyncReport struct {
+	Projects int
+	// UnchangedProjects counts the projects that were not updated since the
+	// last sync and were skipped.
+	UnchangedProjects int
+	FetchedVersions   int
+	DownloadedFiles   int
+	DownloadedBytes   int64
+	SkippedProjects   []string
+}
+
+// Syncer copies projects, their versions and their dependency closure into a store.
+type Syncer struct {
+	Client     *modrinth.ModrinthV2Client
+	Store      *Store
+	HTTPClient *http.Client
+	Options    SyncOptions
+}
+
+// NewSyncer creates a syncer that mirrors into the directory.
+func NewSyncer(client *modrinth.ModrinthV2Client, dir string, options SyncOptions) *Syncer {
+	return &Syncer{
+		Client:     client,
+		Store:      NewStore(dir),
+		HTTPClient: http.DefaultClient,
+		Options:    options,
+	}
+}
+
+// Sync mirrors the projects and every project they depend on. Versions that
+// are already stored are not fetched again and files are only downloaded
+// when their hash is missing.
+func (s *Syncer) Sync(ctx context.Context, projectIDs []string) (*SyncReport, error) {
+	index, err := s.Store.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.syncTags(ctx); err != nil {
+		return nil, err
+	}
+
+	report := &SyncReport{}
+	queued := make(map[string]bool)
+	found := make(map[string]bool)
+	// pinned holds dependency versions that must be mirrored regardless of the filters.
+	pinned := make(map[string][]string)
+	var queue []string
+	enqueue := func(projectID, versionID string) {
+		if versionID != "" && !slices.Contains(pinned[projectID], versionID) {
+			pinned[projectID] = append(pinned[projectID], versionID)
+			if found[projectID] && !slices.Contains(index.Projects[projectID].Versions, versionID) {
+				// The project was synced before the pin was discovered.
+				queued[projectID] = false
+			}
+		}
+		if !queued[projectID] {
+			queued[projectID] = true
+			queue = append(queue, projectID)
+		}
+	}
+	for _, id := range projectIDs {
+		enqueue(id, "")
+	}
+
+	for len(queue) > 0 {
+		projects, err := s.Client.GetProjectsBatch(ctx, queue, modrinth.BatchOptions{})
+		if err != nil {
+			return report, err
+		}
+		queue = nil
+
+		var versionDeps []string
+		for i := range projects {
+			p := &projects[i]
+			found[p.ID] = true
+			found[p.Slug] = true
+			queued[p.ID] = true
+			queued[p.Slug] = true
+			versions, err := s.syncProject(ctx, index, p, pinned[p.ID], report)
+			if err != nil {
+				return report, fmt.Errorf("sync project %s: %w", p.ID, err)
+			}
+			for _, v := range versions {
+				for _, dep := range v.Dependencies {
+					if !s.follows(dep) {
+						continue
+					}
+					switch {
+					case dep.ProjectID != "":
+						enqueue(dep.ProjectID, dep.VersionID)
+					case dep.VersionID != "":
+						versionDeps = append(versionDeps, dep.VersionID)
+					}
+				}
+			}
+		}
+
+		if len(versionDeps) > 0 {
+			versions, err := s.Client.GetProjectVersionsByIDBatch(ctx, versionDeps, modrinth.BatchOptions{})
+			if err != nil {
+				return report, err
+			}
+			for _, v := range versions {
+				enqueue(v.ProjectID, v.ID)
+			}
+		}
+	}
+
+	for _, id := range projectIDs {
+		if !found[id] {
+			report.SkippedProjects = append(report.SkippedProjects, id)
+		}
+	}
+	return report, s.Store.SaveIndex(index)
+}
+
+func (s *Syncer) follows(dep modrinth.VersionDependency) bool {
+	switch dep.DependencyType {
+	case "required":
+		return true
+	case "optional":
+		return s.Options.IncludeOptional
+	}
+	return false
+}
+
+func (s *Syncer) accepts(v *modrinth.ProjectVersion) bool {
+	if len(s.Options.Loaders) > 0 && !slices.ContainsFunc(v.Loaders, func(l string) bool { return slices.Contains(s.Options.Loaders, l) }) {
+		return false
+	}
+	if len(s.Options.GameVersions) > 0 && !slices.ContainsFunc(v.GameVersions, func(g string) bool { return slices.Contains(s.Options.GameVersions, g) }) {
+		return false
+	}
+	return true
+}
+
+// filter returns the ProjectState.Filter of the options.
+func (s *Syncer) filter() string {
+	if len(s.Options.Loaders) == 0 && len(s.Options.GameVersions) == 0 {
+		return ""
+	}
+	loaders := slices.Sorted(slices.Values(s.Options.Loaders))
+	gameVersions := slices.Sorted(slices.Values(s.Options.GameVersions))
+	return "loaders=" + strings.Join(loaders, ",") + ";game_versions=" + strings.Join(gameVersions, ",")
+}
+
+// syncProject stores the project, fetches its missing versions and downloads
+// the files of the accepted versions. It returns the mirrored versions.
+//
+// A project that was not updated since the last sync with the same filters is
+// not fetched again. Otherwise only the bodies of versions that were not seen
+// before are fetched.
+func (s *Syncer) syncProject(ctx context.Context, index *Index, project *modrinth.Project, pinned []string, report *SyncReport) ([]*modrinth.ProjectVersion, error) {
+	filter := s.filter()
+	previous := index.Projects[project.ID]
+	if previous != nil && previous.Filter != filter {
+		previous = nil
+	}
+	if previous != nil && project.Updated != "" && previous.Updated == project.Updated &&
+		!slices.ContainsFunc(pinned, func(id string) bool {
+			return slices.Contains(project.Versions, id) && !slices.Contains(previous.Versions, id)
+		}) {
+		return s.syncUnchanged(ctx, project, previous, report)
+	}
+
+	wanted := project.Versions
+	var rejected []string
+	if filter != "" {
+		wanted = nil
+		if previous == nil {
+			// Let the API apply the filters so rejected versions are never fetched.
+			accepted, err := s.Client.GetProjectVersions(ctx, project.ID, modrinth.GetProjectVersionsOptions{
+				Loaders:      s.Options.Loaders,
+				GameVersions: s.Options.GameVersions,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for i := range accepted {
+				if !s.Store.HasVersion(accepted[i].ID) {
+					if err := s.Store.SaveVersion(&accepted[i]); err != nil {
+						return nil, err
+					}
+					report.FetchedVersions++
+				}
+				wanted = append(wanted, accepted[i].ID)
+			}
+			for _, id := range project.Versions {
+				if !slices.Contains(wanted, id) {
+					rejected = append(rejected, id)
+				}
+			}
+		} else {
+			// Versions seen before keep their verdict; new ones are fetched and filtered below.
+			for _, id := range project.Versions {
+				if slices.Contains(previous.Rejected, id) {
+					rejected = append(rejected, id)
+				} else {
+					wanted = append(wanted, id)
+				}
+			}
+		}
+		for _, id := range pinned {
+			if slices.Contains(project.Versions, id) && !slices.Contains(wanted, id) {
+				wanted = append(wanted, id)
+			}
+		}
+	}
+
+	var missing []string
+	for _, id := range wanted {
+		if !s.Store.HasVersion(id) {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		fetched, err := s.Client.GetProjectVersionsByIDBatch(ctx, missing, modrinth.BatchOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range fetched {
+			if !s.accepts(&fetched[i]) && !slices.Contains(pinned, fetched[i].ID) {
+				rejected = append(rejected, fetched[i].ID)
+				continue
+			}
+			if err := s.Store.SaveVersion(&fetched[i]); err != nil {
+				return nil, err
+			}
+		}
+		report.FetchedVersions += len(fetched)
+	}
+
+	var versions []*modrinth.ProjectVersion
+	for _, id := range wanted {
+		if !s.Store.HasVersion(id) {
+			continue
+		}
+		v, err := s.Store.LoadVersion(id)
+		if err != nil {
+			return nil, err
+		}
+		if s.accepts(v) || slices.Contains(pinned, v.ID) {
+			versions = append(versions, v)
+		}
+	}
+	if err := s.downloadFiles(ctx, versions, report); err != nil {
+		return nil, err
+	}
+
+	if err := s.Store.SaveProject(project); err != nil {
+		return nil, err
+	}
+	if err := s.syncMembers(ctx, project.ID); err != nil {
+		return nil, err
+	}
+	state := &ProjectState{Updated: project.Updated, Filter: filter}
+	if previous, ok := index.Projects[project.ID]; ok {
+		// Keep versions pinned by earlier syncs.
+		for _, id := range previous.Versions {
+			if slices.Contains(project.Versions, id) && !slices.ContainsFunc(versions, func(v *modrinth.ProjectVersion) bool { return v.ID == id }) {
+				v, err := s.Store.LoadVersion(id)
+				if err == nil {
+					versions = append(versions, v)
+				}
+			}
+		}
+	}
+	for _, v := range versions {
+		state.Versions = append(state.Versions, v.ID)
+	}
+	for _, id := range rejected {
+		if !slices.Contains(state.Versions, id) {
+			state.Rejected = append(state.Rejected, id)
+		}
+	}
+	index.Projects[project.ID] = state
+	report.Projects++
+	return versions, nil
+}
+
+// syncUnchanged returns the mirrored versions of a project that was not
+// updated since the last sync. Only files and members that went missing are
+// fetched.
+func (s *Syncer) syncUnchanged(ctx context.Context, project *modrinth.Project, state *ProjectState, report *SyncReport) ([]*modrinth.ProjectVersion, error) {
+	if !s.Store.HasMembers(project.ID) {
+		if err := s.syncMembers(ctx, project.ID); err != nil {
+			return nil, err
+		}
+	}
+	var versions []*modrinth.ProjectVersion
+	for _, id := range state.Versions {
+		v, err := s.Store.LoadVersion(id)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	if err := s.downloadFiles(ctx, versions, report); err != nil {
+		return nil, err
+	}
+	report.Projects++
+	report.UnchangedProjects++
+	return versions, nil
+}
+
+func (s *Syncer) syncMembers(ctx context.Context, projectID string) error {
+	members, err := s.Client.GetProjectTeamMembers(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("sync members: %w", err)
+	}
+	return s.Store.SaveMembers(projectID, members)
+}
+
+func (s *Syncer) downloadFiles(ctx context.Context, versions []*modrinth.ProjectVersion, report *SyncReport) error {
+	concurrency := s.Options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	var files []modrinth.VersionFile
+	queued := make(map[string]bool)
+	for _, v := range versions {
+		for _, f := range v.Files {
+			if sha := f.Hashes["sha1"]; sha != "" && !queued[sha] && !s.Store.HasFile(sha) {
+				queued[sha] = true
+				files = append(files, f)
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	var firstErr error
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, f := range files {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			n, err := s.download(ctx, f)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("download %s: %w", f.Filename, err)
+				}
+				return
+			}
+			report.DownloadedFiles++
+			report.DownloadedBytes += n
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+func (s *Syncer) download(ctx context.Context, file modrinth.VersionFile) (int64, error) {
+	sha := file.Hashes["sha1"]
+	if s.Store.HasFile(sha) {
+		return 0, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var n int64
+	err = writeFileAtomic(s.Store.FilePath(sha), func(f *os.File) error {
+		h := sha1.New()
+		n, err = io.Copy(io.MultiWriter(f, h), resp.Body)
+		if err != nil {
+			return err
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != sha {
+			return fmt.Errorf("sha1 mismatch: got %s, want %s", got, sha)
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (s *Syncer) syncTags(ctx context.Context) error {
+	fetchers := map[string]func(context.Context) (any, error){
+		"game_version":      fetchTag(s.Client.GetGameVersionTags),
+		"loader":            fetchTag(s.Client.GetLoaderTags),
+		"category":          fetchTag(s.Client.GetCategoryTags),
+		"license":           fetchTag(s.Client.GetLicenseTags),
+		"donation_platform": fetchTag(s.Client.GetDonationPlatformTags),
+		"report_type":       fetchTag(s.Client.GetReportTypeTags),
+		"project_type":      fetchTag(s.Client.GetProjectTypeTags),
+		"side_type":         fetchTag(s.Client.GetSideTypeTags),
+	}
+	for _, name := range TagNames {
+		tag, err := fetchers[name](ctx)
+		if err != nil {
+			return fmt.Errorf("sync tag %s: %w", name, err)
+		}
+		if err := s.Store.SaveTag(name, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fetchTag[T any](get func(context.Context) (T, error)) func(context.Context) (any, error) {
+	return func(ctx context.Context) (any, error) {
+		return get(ctx)
+	}
+}
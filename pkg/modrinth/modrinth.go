@@ -38,6 +38,15 @@ func WithHTTPClient(client *http.Client) ModrinthClientOption {
 	}
 }
 
+// WithStrictDecoding makes requests fail with an *UnknownFieldsError when a
+// response has fields the models do not declare. It is meant for tests that
+// should notice when the API changes.
+func WithStrictDecoding() ModrinthClientOption {
+	return func(c *ModrinthV2Client) {
+		c.strict = true
+	}
+}
+
 // NewModrinthV2Client creates a new Modrinth V2 API client.
 func NewModrinthV2Client(options ...ModrinthClientOption) *ModrinthV2Client {
 	c := &ModrinthV2Client{
@@ -66,22 +75,29 @@ func (c *ModrinthV2Client) request(ctx context.Context, method, path string, bod
 	return c.httpClient.Do(req)
 }
 
-func (c *ModrinthV2Client) doJSON(ctx context.Context, method, path string, body any, result any) error {
+func (c *ModrinthV2Client) send(ctx context.Context, method, path string, body any) (*http.Response, error) {
 	var r io.Reader
 	var ct string
 	if body != nil {
 		b, err := json.Marshal(body)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		r = bytes.NewReader(b)
 		ct = "application/json"
 	}
 	resp, err := c.request(ctx, method, path, r, ct)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+func checkResponse(resp *http.Response) error {
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
 		return &ModrinthAPIError{
@@ -90,12 +106,32 @@ func (c *ModrinthV2Client) doJSON(ctx context.Context, method, path string, body
 			Body:   string(b),
 		}
 	}
+	return nil
+}
+
+func (c *ModrinthV2Client) doJSON(ctx context.Context, method, path string, body any, result any) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 	if result != nil {
-		return json.NewDecoder(resp.Body).Decode(result)
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return err
+		}
+		return c.checkStrict(result)
 	}
 	return nil
 }
 
+// checkStrict returns an error for unknown fields in a decoded result in strict decoding mode.
+func (c *ModrinthV2Client) checkStrict(result any) error {
+	if !c.strict {
+		return nil
+	}
+	return CheckUnknownFields(result)
+}
+
 // SearchProjects searches for projects on Modrinth.
 func (c *ModrinthV2Client) SearchProjects(ctx context.Context, options SearchProjectOptions) (*SearchResult, error) {
 	v := url.Values{}
@@ -132,10 +168,35 @@ func (c *ModrinthV2Client) GetProject(ctx context.Context, projectID string) (*P
 
 // GetProjects fetches multiple projects by IDs.
 func (c *ModrinthV2Client) GetProjects(ctx context.Context, projectIDs []string) ([]Project, error) {
+	path := idsPath("/v2/projects", projectIDs)
+	var projects []Project
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &projects)
+	return projects, err
+}
+
+// GetProjectDependencies fetches all projects and versions a project depends on.
+func (c *ModrinthV2Client) GetProjectDependencies(ctx context.Context, projectID string) (*ProjectDependencies, error) {
+	path := "/v2/project/" + projectID + "/dependencies"
+	var dependencies ProjectDependencies
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &dependencies)
+	return &dependencies, err
+}
+
+// CheckProjectValidity checks whether a project ID or slug exists and returns its project ID.
+func (c *ModrinthV2Client) CheckProjectValidity(ctx context.Context, idOrSlug string) (string, error) {
+	path := "/v2/project/" + idOrSlug + "/check"
+	var result struct {
+		ID string `json:"id"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &result)
+	return result.ID, err
+}
+
+// GetRandomProjects fetches a number of random projects.
+func (c *ModrinthV2Client) GetRandomProjects(ctx context.Context, count int) ([]Project, error) {
 	v := url.Values{}
-	b, _ := json.Marshal(projectIDs)
-	v.Add("ids", string(b))
-	path := "/v2/projects?" + v.Encode()
+	v.Add("count", fmt.Sprint(count))
+	path := "/v2/projects_random?" + v.Encode()
 	var projects []Project
 	err := c.doJSON(ctx, http.MethodGet, path, nil, &projects)
 	return projects, err
@@ -143,28 +204,16 @@ func (c *ModrinthV2Client) GetProjects(ctx context.Context, projectIDs []string)
 
 // GetProjectVersions fetches versions for a project.
 func (c *ModrinthV2Client) GetProjectVersions(ctx context.Context, projectID string, options GetProjectVersionsOptions) ([]ProjectVersion, error) {
-	v := url.Values{}
-	if len(options.Loaders) > 0 {
-		b, err := json.Marshal(options.Loaders)
-		if err != nil {
-			return nil, err
-		}
-		v.Add("loaders", string(b))
-	}
-	if len(options.GameVersions) > 0 {
-		b, err := json.Marshal(options.GameVersions)
-		if err != nil {
-			return nil, err
-		}
-		v.Add("game_versions", string(b))
-	}
-	if options.Featured != nil {
-		v.Add("featured", fmt.Sprintf("%t", *options.Featured))
+	path, err := projectVersionsPath(projectID, options)
+	if err != nil {
+		return nil, err
 	}
-	path := "/v2/project/" + projectID + "/version?" + v.Encode()
 	var versions []ProjectVersion
-	err := c.doJSON(ctx, http.MethodGet, path, nil, &versions)
-	return versions, err
+	err = c.doJSON(ctx, http.MethodGet, path, nil, &versions)
+	if err != nil {
+		return versions, err
+	}
+	return FilterVersions(versions, options), nil
 }
 
 // GetProjectVersion fetches a single project version by ID.
@@ -175,12 +224,17 @@ func (c *ModrinthV2Client) GetProjectVersion(ctx context.Context, versionID stri
 	return &version, err
 }
 
+// GetVersionByNumber fetches a project version by its ID or version number.
+func (c *ModrinthV2Client) GetVersionByNumber(ctx context.Context, projectID, number string) (*ProjectVersion, error) {
+	path := "/v2/project/" + projectID + "/version/" + url.PathEscape(number)
+	var version ProjectVersion
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &version)
+	return &version, err
+}
+
 // GetProjectVersionsByID fetches multiple project versions by IDs.
 func (c *ModrinthV2Client) GetProjectVersionsByID(ctx context.Context, ids []string) ([]ProjectVersion, error) {
-	v := url.Values{}
-	b, _ := json.Marshal(ids)
-	v.Add("ids", string(b))
-	path := "/v2/versions?" + v.Encode()
+	path := idsPath("/v2/versions", ids)
 	var versions []ProjectVersion
 	err := c.doJSON(ctx, http.MethodGet, path, nil, &versions)
 	return versions, err
@@ -198,6 +252,19 @@ func (c *ModrinthV2Client) GetProjectVersionsByHash(ctx context.Context, hashes
 	return versions, err
 }
 
+// GetVersionFromHash fetches the project version of a single file hash.
+func (c *ModrinthV2Client) GetVersionFromHash(ctx context.Context, hash string, algorithm string) (*ProjectVersion, error) {
+	if algorithm == "" {
+		algorithm = "sha1"
+	}
+	v := url.Values{}
+	v.Add("algorithm", algorithm)
+	path := "/v2/version_file/" + hash + "?" + v.Encode()
+	var version ProjectVersion
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &version)
+	return &version, err
+}
+
 // GetLatestVersionsFromHashes fetches the latest versions matching the hashes and filters.
 func (c *ModrinthV2Client) GetLatestVersionsFromHashes(ctx context.Context, hashes []string, algorithm string, loaders []string, gameVersions []string) (map[string]ProjectVersion, error) {
 	body := map[string]any{
@@ -261,6 +328,46 @@ func (c *ModrinthV2Client) GetLoaderTags(ctx context.Context) ([]Loader, error)
 	return loaders, err
 }
 
+// GetDonationPlatformTags fetches available donation platform tags.
+func (c *ModrinthV2Client) GetDonationPlatformTags(ctx context.Context) ([]DonationPlatform, error) {
+	path := "/v2/tag/donation_platform"
+	var platforms []DonationPlatform
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &platforms)
+	return platforms, err
+}
+
+// GetReportTypeTags fetches available report type tags.
+func (c *ModrinthV2Client) GetReportTypeTags(ctx context.Context) ([]string, error) {
+	path := "/v2/tag/report_type"
+	var types []string
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &types)
+	return types, err
+}
+
+// GetProjectTypeTags fetches available project type tags.
+func (c *ModrinthV2Client) GetProjectTypeTags(ctx context.Context) ([]string, error) {
+	path := "/v2/tag/project_type"
+	var types []string
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &types)
+	return types, err
+}
+
+// GetSideTypeTags fetches available side type tags.
+func (c *ModrinthV2Client) GetSideTypeTags(ctx context.Context) ([]string, error) {
+	path := "/v2/tag/side_type"
+	var types []string
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &types)
+	return types, err
+}
+
+// GetStatistics fetches statistics about the Modrinth instance.
+func (c *ModrinthV2Client) GetStatistics(ctx context.Context) (*Statistics, error) {
+	path := "/v2/statistics"
+	var statistics Statistics
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &statistics)
+	return &statistics, err
+}
+
 // GetCollections fetches collections for a user.
 func (c *ModrinthV2Client) GetCollections(ctx context.Context, userID string) ([]Collection, error) {
 	path := "/v3/user/" + userID + "/collections"
@@ -269,6 +376,14 @@ func (c *ModrinthV2Client) GetCollections(ctx context.Context, userID string) ([
 	return collections, err
 }
 
+// GetCollection fetches a collection by ID.
+func (c *ModrinthV2Client) GetCollection(ctx context.Context, collectionID string) (*Collection, error) {
+	path := "/v3/collection/" + collectionID
+	var collection Collection
+	err := c.doJSON(ctx, http.MethodGet, path, nil, &collection)
+	return &collection, err
+}
+
 // UpdateCollectionIcon updates the icon for a collection.
 func (c *ModrinthV2Client) UpdateCollectionIcon(ctx context.Context, collectionID string, iconData []byte, mimeType string) error {
 	extParts := strings.Split(mimeType, "/")
@@ -282,15 +397,7 @@ func (c *ModrinthV2Client) UpdateCollectionIcon(ctx context.Context, collectionI
 		return err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(resp.Body)
-		return &ModrinthAPIError{
-			URL:    resp.Request.URL.String(),
-			Status: resp.StatusCode,
-			Body:   string(b),
-		}
-	}
-	return nil
+	return checkResponse(resp)
 }
 
 // CreateCollection creates a new collection.
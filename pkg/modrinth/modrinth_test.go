@@ -194,3 +194,328 @@ func TestGetProjects(t *testing.T) {
 		})
 	}
 }
+
+func TestGetProjectDependencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		projectID string
+		expected  *modrinth.ProjectDependencies
+		status    int
+		body      string
+		wantErr   bool
+	}{
+		{
+			name:      "Successful get dependencies",
+			projectID: "AANobbMI",
+			expected: &modrinth.ProjectDependencies{
+				Projects: []modrinth.Project{{ID: "P7dR8mSH", Slug: "fabric-api"}},
+				Versions: []modrinth.ProjectVersion{{ID: "IQ3UGSc2", ProjectID: "P7dR8mSH"}},
+			},
+			status:  200,
+			body:    `{"projects":[{"id":"P7dR8mSH","slug":"fabric-api"}],"versions":[{"id":"IQ3UGSc2","project_id":"P7dR8mSH"}]}`,
+			wantErr: false,
+		},
+		{
+			name:      "API error",
+			projectID: "invalid",
+			status:    404,
+			body:      "Project not found",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v2/project/"+tt.projectID+"/dependencies" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+			defer server.Close()
+
+			result, err := client.GetProjectDependencies(context.Background(), tt.projectID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetProjectDependencies() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("GetProjectDependencies() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetVersionByNumber(t *testing.T) {
+	tests := []struct {
+		name     string
+		number   string
+		path     string
+		expected *modrinth.ProjectVersion
+		status   int
+		body     string
+		wantErr  bool
+	}{
+		{
+			name:     "Successful get version by number",
+			number:   "0.92.2+1.20.1",
+			path:     "/v2/project/fabric-api/version/0.92.2+1.20.1",
+			expected: &modrinth.ProjectVersion{ID: "IQ3UGSc2", VersionNumber: "0.92.2+1.20.1"},
+			status:   200,
+			body:     `{"id":"IQ3UGSc2","version_number":"0.92.2+1.20.1"}`,
+			wantErr:  false,
+		},
+		{
+			name:    "API error",
+			number:  "0.0.0",
+			path:    "/v2/project/fabric-api/version/0.0.0",
+			status:  404,
+			body:    "Version not found",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					t.Errorf("unexpected path %s, want %s", r.URL.Path, tt.path)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+			defer server.Close()
+
+			result, err := client.GetVersionByNumber(context.Background(), "fabric-api", tt.number)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetVersionByNumber() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("GetVersionByNumber() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckProjectValidity(t *testing.T) {
+	tests := []struct {
+		name     string
+		idOrSlug string
+		expected string
+		status   int
+		body     string
+		wantErr  bool
+	}{
+		{
+			name:     "Valid slug",
+			idOrSlug: "fabric-api",
+			expected: "P7dR8mSH",
+			status:   200,
+			body:     `{"id":"P7dR8mSH"}`,
+			wantErr:  false,
+		},
+		{
+			name:     "Invalid slug",
+			idOrSlug: "does-not-exist",
+			status:   404,
+			body:     "",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+			defer server.Close()
+
+			result, err := client.CheckProjectValidity(context.Background(), tt.idOrSlug)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckProjectValidity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && result != tt.expected {
+				t.Errorf("CheckProjectValidity() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetRandomProjects(t *testing.T) {
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("count"); got != "2" {
+			t.Errorf("count = %s, want 2", got)
+		}
+		fmt.Fprint(w, `[{"id":"a"},{"id":"b"}]`)
+	})
+	defer server.Close()
+
+	result, err := client.GetRandomProjects(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetRandomProjects() error = %v", err)
+	}
+	expected := []modrinth.Project{{ID: "a"}, {ID: "b"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("GetRandomProjects() = %v, want %v", result, expected)
+	}
+}
+
+func TestGetStatistics(t *testing.T) {
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"projects":10,"versions":20,"files":30,"authors":5}`)
+	})
+	defer server.Close()
+
+	result, err := client.GetStatistics(context.Background())
+	if err != nil {
+		t.Fatalf("GetStatistics() error = %v", err)
+	}
+	expected := &modrinth.Statistics{Projects: 10, Versions: 20, Files: 30, Authors: 5}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("GetStatistics() = %v, want %v", result, expected)
+	}
+}
+
+func TestGetVersionFromHash(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		expected  *modrinth.ProjectVersion
+		status    int
+		body      string
+		wantErr   bool
+	}{
+		{
+			name:      "Default algorithm",
+			algorithm: "",
+			expected:  &modrinth.ProjectVersion{ID: "IQ3UGSc2"},
+			status:    200,
+			body:      `{"id":"IQ3UGSc2"}`,
+			wantErr:   false,
+		},
+		{
+			name:      "API error",
+			algorithm: "sha512",
+			status:    404,
+			body:      "Not found",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+				want := tt.algorithm
+				if want == "" {
+					want = "sha1"
+				}
+				if got := r.URL.Query().Get("algorithm"); got != want {
+					t.Errorf("algorithm = %s, want %s", got, want)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+			defer server.Close()
+
+			result, err := client.GetVersionFromHash(context.Background(), "abc", tt.algorithm)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetVersionFromHash() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("GetVersionFromHash() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetStringTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		fetch func(*modrinth.ModrinthV2Client) ([]string, error)
+	}{
+		{
+			name:  "Side types",
+			path:  "/v2/tag/side_type",
+			fetch: func(c *modrinth.ModrinthV2Client) ([]string, error) { return c.GetSideTypeTags(context.Background()) },
+		},
+		{
+			name: "Project types",
+			path: "/v2/tag/project_type",
+			fetch: func(c *modrinth.ModrinthV2Client) ([]string, error) {
+				return c.GetProjectTypeTags(context.Background())
+			},
+		},
+		{
+			name:  "Report types",
+			path:  "/v2/tag/report_type",
+			fetch: func(c *modrinth.ModrinthV2Client) ([]string, error) { return c.GetReportTypeTags(context.Background()) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					t.Errorf("unexpected path %s, want %s", r.URL.Path, tt.path)
+				}
+				fmt.Fprint(w, `["a","b"]`)
+			})
+			defer server.Close()
+
+			result, err := tt.fetch(client)
+			if err != nil {
+				t.Fatalf("fetch error = %v", err)
+			}
+			if !reflect.DeepEqual(result, []string{"a", "b"}) {
+				t.Errorf("fetch = %v, want [a b]", result)
+			}
+		})
+	}
+}
+
+func TestGetDonationPlatformTags(t *testing.T) {
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"short":"patreon","name":"Patreon"}]`)
+	})
+	defer server.Close()
+
+	result, err := client.GetDonationPlatformTags(context.Background())
+	if err != nil {
+		t.Fatalf("GetDonationPlatformTags() error = %v", err)
+	}
+	expected := []modrinth.DonationPlatform{{Short: "patreon", Name: "Patreon"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("GetDonationPlatformTags() = %v, want %v", result, expected)
+	}
+}
+
+func TestProjectColor(t *testing.T) {
+	color := 0x1bd96a
+	tests := []struct {
+		name     string
+		project  modrinth.Project
+		expected string
+	}{
+		{name: "Color", project: modrinth.Project{Color: &color}, expected: "#1bd96a"},
+		{name: "No color", project: modrinth.Project{}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.project.HexColor(); got != tt.expected {
+				t.Errorf("HexColor() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	project := modrinth.Project{Color: &color}
+	if r, g, b, ok := project.RGB(); !ok || r != 0x1b || g != 0xd9 || b != 0x6a {
+		t.Errorf("RGB() = %d, %d, %d, %v", r, g, b, ok)
+	}
+}
@@ -0,0 +1,135 @@
+package modrinth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// ErrStopStream can be returned from a stream callback to stop reading the
+// response early. The stream then returns nil.
+var ErrStopStream = errors.New("stop stream")
+
+// streamArray sends a request whose response is a JSON array and decodes it
+// one element at a time, so the whole array is never held in memory. The
+// response body is closed as soon as fn returns an error.
+func streamArray[T any](ctx context.Context, c *ModrinthV2Client, method, path string, body any, fn func(*T) error) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		// A null response is an empty array.
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("%s: expected a JSON array, got %v", path, tok)
+	}
+	for dec.More() {
+		var item T
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		if err := c.checkStrict(&item); err != nil {
+			return err
+		}
+		if err := fn(&item); err != nil {
+			if errors.Is(err, ErrStopStream) {
+				return nil
+			}
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// seqArray adapts streamArray to an iterator. Breaking out of the loop closes the response.
+func seqArray[T any](ctx context.Context, c *ModrinthV2Client, path string, filter func(*T) bool) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		stopped := false
+		err := streamArray(ctx, c, http.MethodGet, path, nil, func(item *T) error {
+			if filter != nil && !filter(item) {
+				return nil
+			}
+			if !yield(item, nil) {
+				stopped = true
+				return ErrStopStream
+			}
+			return nil
+		})
+		if err != nil && !stopped {
+			yield(nil, err)
+		}
+	}
+}
+
+func projectVersionsPath(projectID string, options GetProjectVersionsOptions) (string, error) {
+	v, err := options.query()
+	if err != nil {
+		return "", err
+	}
+	return "/v2/project/" + projectID + "/version?" + v.Encode(), nil
+}
+
+func idsPath(base string, ids []string) string {
+	v := url.Values{}
+	b, _ := json.Marshal(ids)
+	v.Add("ids", string(b))
+	return base + "?" + v.Encode()
+}
+
+// StreamProjectVersions fetches the versions of a project like
+// GetProjectVersions, but decodes and passes them to fn one at a time.
+// Return ErrStopStream from fn to stop early.
+func (c *ModrinthV2Client) StreamProjectVersions(ctx context.Context, projectID string, options GetProjectVersionsOptions, fn func(*ProjectVersion) error) error {
+	path, err := projectVersionsPath(projectID, options)
+	if err != nil {
+		return err
+	}
+	return streamArray(ctx, c, http.MethodGet, path, nil, func(v *ProjectVersion) error {
+		if !options.Matches(v) {
+			return nil
+		}
+		return fn(v)
+	})
+}
+
+// ProjectVersionsSeq returns an iterator over the versions of a project that
+// decodes them one at a time. A request error is yielded as the last element.
+func (c *ModrinthV2Client) ProjectVersionsSeq(ctx context.Context, projectID string, options GetProjectVersionsOptions) iter.Seq2[*ProjectVersion, error] {
+	path, err := projectVersionsPath(projectID, options)
+	if err != nil {
+		return func(yield func(*ProjectVersion, error) bool) { yield(nil, err) }
+	}
+	return seqArray(ctx, c, path, options.Matches)
+}
+
+// StreamProjectVersionsByID fetches versions by IDs like GetProjectVersionsByID,
+// but decodes and passes them to fn one at a time.
+func (c *ModrinthV2Client) StreamProjectVersionsByID(ctx context.Context, ids []string, fn func(*ProjectVersion) error) error {
+	return streamArray(ctx, c, http.MethodGet, idsPath("/v2/versions", ids), nil, fn)
+}
+
+// StreamProjects fetches projects by IDs or slugs like GetProjects, but
+// decodes and passes them to fn one at a time.
+func (c *ModrinthV2Client) StreamProjects(ctx context.Context, projectIDs []string, fn func(*Project) error) error {
+	return streamArray(ctx, c, http.MethodGet, idsPath("/v2/projects", projectIDs), nil, fn)
+}
+
+// ProjectsSeq returns an iterator over projects by IDs or slugs that decodes
+// them one at a time. A request error is yielded as the last element.
+func (c *ModrinthV2Client) ProjectsSeq(ctx context.Context, projectIDs []string) iter.Seq2[*Project, error] {
+	return seqArray[Project](ctx, c, idsPath("/v2/projects", projectIDs), nil)
+}
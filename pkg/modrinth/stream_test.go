@@ -0,0 +1,127 @@
+package modrinth_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+func versionsJSON(n int) string {
+	var items []string
+	for i := range n {
+		versionType := "release"
+		if i%2 == 1 {
+			versionType = "beta"
+		}
+		items = append(items, fmt.Sprintf(`{"id":"v%d","version_type":%q}`, i, versionType))
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
+
+func TestStreamProjectVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		status   int
+		options  modrinth.GetProjectVersionsOptions
+		stopAt   int
+		expected []string
+		wantErr  bool
+	}{
+		{name: "All", body: versionsJSON(4), status: http.StatusOK, expected: []string{"v0", "v1", "v2", "v3"}},
+		{name: "Client-side filter", body: versionsJSON(4), status: http.StatusOK, options: modrinth.GetProjectVersionsOptions{VersionTypes: []string{"beta"}}, expected: []string{"v1", "v3"}},
+		{name: "Early stop", body: versionsJSON(1000), status: http.StatusOK, stopAt: 2, expected: []string{"v0", "v1"}},
+		{name: "Null", body: `null`, status: http.StatusOK},
+		{name: "Not an array", body: `{"id":"v0"}`, status: http.StatusOK, wantErr: true},
+		{name: "Truncated", body: `[{"id":"v0"},{"id":`, status: http.StatusOK, expected: []string{"v0"}, wantErr: true},
+		{name: "API error", body: `{"error":"not_found"}`, status: http.StatusNotFound, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+			defer server.Close()
+
+			var got []string
+			err := client.StreamProjectVersions(context.Background(), "AABBCCDD", tt.options, func(v *modrinth.ProjectVersion) error {
+				got = append(got, v.ID)
+				if tt.stopAt > 0 && len(got) == tt.stopAt {
+					return modrinth.ErrStopStream
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StreamProjectVersions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("StreamProjectVersions() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStreamCallbackError(t *testing.T) {
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":"AAAA"},{"id":"BBBB"}]`)
+	})
+	defer server.Close()
+
+	failure := errors.New("failure")
+	err := client.StreamProjects(context.Background(), []string{"AAAA", "BBBB"}, func(p *modrinth.Project) error {
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("StreamProjects() error = %v, want %v", err, failure)
+	}
+}
+
+func TestProjectVersionsSeq(t *testing.T) {
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/project/AABBCCDD/version" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		fmt.Fprint(w, versionsJSON(100))
+	})
+	defer server.Close()
+
+	var got []string
+	for v, err := range client.ProjectVersionsSeq(context.Background(), "AABBCCDD", modrinth.GetProjectVersionsOptions{VersionTypes: []string{"release"}}) {
+		if err != nil {
+			t.Fatalf("ProjectVersionsSeq() error = %v", err)
+		}
+		got = append(got, v.ID)
+		if len(got) == 3 {
+			break
+		}
+	}
+	if expected := []string{"v0", "v2", "v4"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("ProjectVersionsSeq() = %v, want %v", got, expected)
+	}
+}
+
+func TestProjectsSeqError(t *testing.T) {
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer server.Close()
+
+	var errs int
+	for p, err := range client.ProjectsSeq(context.Background(), []string{"AAAA"}) {
+		if p != nil || err == nil {
+			t.Errorf("ProjectsSeq() = %v, %v, want only an error", p, err)
+		}
+		errs++
+	}
+	if errs != 1 {
+		t.Errorf("ProjectsSeq() yielded %d errors, want 1", errs)
+	}
+}
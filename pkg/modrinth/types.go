@@ -1,8 +1,11 @@
 package modrinth
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"reflect"
+	"time"
 )
 
 // ModrinthAPIError represents an error from the Modrinth API.
@@ -18,29 +21,30 @@ func (e *ModrinthAPIError) Error() string {
 
 // SearchResultHit represents a single hit in search results.
 type SearchResultHit struct {
-	Slug               string   `json:"slug"`
-	ProjectID          string   `json:"project_id"`
-	ProjectType        string   `json:"project_type"`
-	Author             string   `json:"author"`
-	Title              string   `json:"title"`
-	Description        string   `json:"description"`
-	Categories         []string `json:"categories"`
-	Versions           []string `json:"versions"`
-	Downloads          int      `json:"downloads"`
-	Follows            int      `json:"follows"`
-	PageURL            string   `json:"page_url"`
-	IconURL            string   `json:"icon_url"`
-	AuthorURL          string   `json:"author_url"`
-	DateCreated        string   `json:"date_created"`
-	DateModified       string   `json:"date_modified"`
-	LatestVersion      string   `json:"latest_version"`
-	License            string   `json:"license"`
-	ClientSide         string   `json:"client_side"`
-	ServerSide         string   `json:"server_side"`
-	Host               string   `json:"host"`
-	Gallery            []string `json:"gallery"`
-	FeaturedGallery    string   `json:"featured_gallery"`
-	MonetizationStatus string   `json:"monetization_status"`
+	Slug               string                     `json:"slug"`
+	ProjectID          string                     `json:"project_id"`
+	ProjectType        string                     `json:"project_type"`
+	Author             string                     `json:"author"`
+	Title              string                     `json:"title"`
+	Description        string                     `json:"description"`
+	Categories         []string                   `json:"categories"`
+	Versions           []string                   `json:"versions"`
+	Downloads          int                        `json:"downloads"`
+	Follows            int                        `json:"follows"`
+	PageURL            string                     `json:"page_url"`
+	IconURL            string                     `json:"icon_url"`
+	AuthorURL          string                     `json:"author_url"`
+	DateCreated        string                     `json:"date_created"`
+	DateModified       string                     `json:"date_modified"`
+	LatestVersion      string                     `json:"latest_version"`
+	License            string                     `json:"license"`
+	ClientSide         string                     `json:"client_side"`
+	ServerSide         string                     `json:"server_side"`
+	Host               string                     `json:"host"`
+	Gallery            []string                   `json:"gallery"`
+	FeaturedGallery    string                     `json:"featured_gallery"`
+	MonetizationStatus string                     `json:"monetization_status"`
+	Extra              map[string]json.RawMessage `json:"-"`
 }
 
 // SearchProjectOptions defines options for searching projects.
@@ -55,10 +59,11 @@ type SearchProjectOptions struct {
 
 // SearchResult represents the result of a project search.
 type SearchResult struct {
-	Hits      []SearchResultHit `json:"hits"`
-	Offset    int               `json:"offset"`
-	Limit     int               `json:"limit"`
-	TotalHits int               `json:"total_hits"`
+	Hits      []SearchResultHit          `json:"hits"`
+	Offset    int                        `json:"offset"`
+	Limit     int                        `json:"limit"`
+	TotalHits int                        `json:"total_hits"`
+	Extra     map[string]json.RawMessage `json:"-"`
 }
 
 // GetProjectVersionsOptions defines options for fetching project versions.
@@ -66,165 +71,307 @@ type GetProjectVersionsOptions struct {
 	Loaders      []string `json:"-"`
 	GameVersions []string `json:"-"`
 	Featured     *bool    `json:"-"`
+	// IncludeChangelog controls whether changelogs are returned. The API includes them by default.
+	IncludeChangelog *bool `json:"-"`
+
+	// The following filters are applied on the client side.
+
+	// VersionTypes keeps only versions of the given types (release, beta, alpha).
+	VersionTypes []string `json:"-"`
+	// PublishedAfter keeps only versions published at or after this time.
+	PublishedAfter time.Time `json:"-"`
+	// PublishedBefore keeps only versions published before this time.
+	PublishedBefore time.Time `json:"-"`
+	// PrimaryFileOnly keeps only versions that have a primary file.
+	PrimaryFileOnly bool `json:"-"`
 }
 
 // Project represents a Modrinth project.
 type Project struct {
-	ID                 string            `json:"id"`
-	Slug               string            `json:"slug"`
-	ProjectType        string            `json:"project_type"`
-	Team               string            `json:"team"`
-	Title              string            `json:"title"`
-	Description        string            `json:"description"`
-	Body               string            `json:"body"`
-	Published          string            `json:"published"`
-	Updated            string            `json:"updated"`
-	Approved           string            `json:"approved,omitempty"`
-	Status             string            `json:"status"`
-	RequestedStatus    string            `json:"requested_status,omitempty"`
-	ModeratorMessage   *ModeratorMessage `json:"moderator_message,omitempty"`
-	License            License           `json:"license"`
-	ClientSide         string            `json:"client_side"`
-	ServerSide         string            `json:"server_side"`
-	Downloads          int               `json:"downloads"`
-	Followers          int               `json:"followers"`
-	Categories         []string          `json:"categories"`
-	Versions           []string          `json:"versions"`
-	IconURL            string            `json:"icon_url,omitempty"`
-	Color              *int              `json:"color,omitempty"`
-	ThreadID           string            `json:"thread_id,omitempty"`
-	MonetizationStatus string            `json:"monetization_status"`
-	IssuesURL          string            `json:"issues_url,omitempty"`
-	SourceURL          string            `json:"source_url,omitempty"`
-	WikiURL            string            `json:"wiki_url,omitempty"`
-	DiscordURL         string            `json:"discord_url,omitempty"`
-	DonationURLs       []DonationURL     `json:"donation_urls,omitempty"`
-	Gallery            []GalleryItem     `json:"gallery,omitempty"`
+	ID                 string                     `json:"id"`
+	Slug               string                     `json:"slug"`
+	ProjectType        string                     `json:"project_type"`
+	Team               string                     `json:"team"`
+	Title              string                     `json:"title"`
+	Description        string                     `json:"description"`
+	Body               string                     `json:"body"`
+	Published          string                     `json:"published"`
+	Updated            string                     `json:"updated"`
+	Approved           string                     `json:"approved,omitempty"`
+	Status             string                     `json:"status"`
+	RequestedStatus    string                     `json:"requested_status,omitempty"`
+	ModeratorMessage   *ModeratorMessage          `json:"moderator_message,omitempty"`
+	License            License                    `json:"license"`
+	ClientSide         string                     `json:"client_side"`
+	ServerSide         string                     `json:"server_side"`
+	Downloads          int                        `json:"downloads"`
+	Followers          int                        `json:"followers"`
+	Categories         []string                   `json:"categories"`
+	Versions           []string                   `json:"versions"`
+	IconURL            string                     `json:"icon_url,omitempty"`
+	Color              *int                       `json:"color,omitempty"`
+	ThreadID           string                     `json:"thread_id,omitempty"`
+	MonetizationStatus string                     `json:"monetization_status"`
+	IssuesURL          string                     `json:"issues_url,omitempty"`
+	SourceURL          string                     `json:"source_url,omitempty"`
+	WikiURL            string                     `json:"wiki_url,omitempty"`
+	DiscordURL         string                     `json:"discord_url,omitempty"`
+	DonationURLs       []DonationURL              `json:"donation_urls,omitempty"`
+	Gallery            []GalleryItem              `json:"gallery,omitempty"`
+	Extra              map[string]json.RawMessage `json:"-"`
+}
+
+// RGB returns the red, green and blue components of the project color, which
+// Modrinth derives from the icon. ok is false if the project has no color.
+func (p *Project) RGB() (r, g, b uint8, ok bool) {
+	if p.Color == nil {
+		return 0, 0, 0, false
+	}
+	c := *p.Color
+	return uint8(c >> 16), uint8(c >> 8), uint8(c), true
+}
+
+// HexColor returns the project color as "#rrggbb", or an empty string if the project has no color.
+func (p *Project) HexColor() string {
+	r, g, b, ok := p.RGB()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
 }
 
 // ModeratorMessage represents a moderator message for a project.
 type ModeratorMessage struct {
-	Message string `json:"message"`
-	Body    string `json:"body"`
+	Message string                     `json:"message"`
+	Body    string                     `json:"body"`
+	Extra   map[string]json.RawMessage `json:"-"`
 }
 
 // DonationURL represents a donation link for a project.
 type DonationURL struct {
-	ID       string `json:"id"`
-	Platform string `json:"platform"`
-	URL      string `json:"url"`
+	ID       string                     `json:"id"`
+	Platform string                     `json:"platform"`
+	URL      string                     `json:"url"`
+	Extra    map[string]json.RawMessage `json:"-"`
 }
 
 // GalleryItem represents an item in a project's gallery.
 type GalleryItem struct {
-	URL         string `json:"url"`
-	Featured    bool   `json:"featured"`
-	Title       string `json:"title,omitempty"`
-	Description string `json:"description,omitempty"`
-	Created     string `json:"created"`
-	Ordering    int    `json:"ordering"`
+	URL         string                     `json:"url"`
+	Featured    bool                       `json:"featured"`
+	Title       string                     `json:"title,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Created     string                     `json:"created"`
+	Ordering    int                        `json:"ordering"`
+	Extra       map[string]json.RawMessage `json:"-"`
 }
 
 // ProjectVersion represents a version of a Modrinth project.
 type ProjectVersion struct {
-	ID              string              `json:"id"`
-	ProjectID       string              `json:"project_id"`
-	AuthorID        string              `json:"author_id"`
-	Name            string              `json:"name"`
-	VersionNumber   string              `json:"version_number"`
-	Changelog       string              `json:"changelog,omitempty"`
-	DatePublished   string              `json:"date_published"`
-	Downloads       int                 `json:"downloads"`
-	VersionType     string              `json:"version_type"`
-	Status          string              `json:"status"`
-	RequestedStatus string              `json:"requested_status,omitempty"`
-	Files           []VersionFile       `json:"files"`
-	Dependencies    []VersionDependency `json:"dependencies"`
-	GameVersions    []string            `json:"game_versions"`
-	Loaders         []string            `json:"loaders"`
-	Featured        bool                `json:"featured"`
+	ID              string                     `json:"id"`
+	ProjectID       string                     `json:"project_id"`
+	AuthorID        string                     `json:"author_id"`
+	Name            string                     `json:"name"`
+	VersionNumber   string                     `json:"version_number"`
+	Changelog       string                     `json:"changelog,omitempty"`
+	DatePublished   string                     `json:"date_published"`
+	Downloads       int                        `json:"downloads"`
+	VersionType     string                     `json:"version_type"`
+	Status          string                     `json:"status"`
+	RequestedStatus string                     `json:"requested_status,omitempty"`
+	Files           []VersionFile              `json:"files"`
+	Dependencies    []VersionDependency        `json:"dependencies"`
+	GameVersions    []string                   `json:"game_versions"`
+	Loaders         []string                   `json:"loaders"`
+	Featured        bool                       `json:"featured"`
+	Extra           map[string]json.RawMessage `json:"-"`
 }
 
 // VersionFile represents a file in a project version.
 type VersionFile struct {
-	Hashes   map[string]string `json:"hashes"`
-	URL      string            `json:"url"`
-	Filename string            `json:"filename"`
-	Primary  bool              `json:"primary"`
-	Size     int               `json:"size"`
-	FileType string            `json:"file_type,omitempty"`
+	Hashes   map[string]string          `json:"hashes"`
+	URL      string                     `json:"url"`
+	Filename string                     `json:"filename"`
+	Primary  bool                       `json:"primary"`
+	Size     int                        `json:"size"`
+	FileType string                     `json:"file_type,omitempty"`
+	Extra    map[string]json.RawMessage `json:"-"`
 }
 
 // VersionDependency represents a dependency in a project version.
 type VersionDependency struct {
-	VersionID      string `json:"version_id,omitempty"`
-	ProjectID      string `json:"project_id,omitempty"`
-	FileName       string `json:"file_name,omitempty"`
-	DependencyType string `json:"dependency_type"`
+	VersionID      string                     `json:"version_id,omitempty"`
+	ProjectID      string                     `json:"project_id,omitempty"`
+	FileName       string                     `json:"file_name,omitempty"`
+	DependencyType string                     `json:"dependency_type"`
+	Extra          map[string]json.RawMessage `json:"-"`
+}
+
+// ProjectDependencies represents all projects and versions a project depends on.
+type ProjectDependencies struct {
+	Projects []Project                  `json:"projects"`
+	Versions []ProjectVersion           `json:"versions"`
+	Extra    map[string]json.RawMessage `json:"-"`
 }
 
 // Category represents a Modrinth category tag.
 type Category struct {
-	Icon        string `json:"icon"`
-	Name        string `json:"name"`
-	ProjectType string `json:"project_type"`
-	Header      string `json:"header"`
+	Icon        string                     `json:"icon"`
+	Name        string                     `json:"name"`
+	ProjectType string                     `json:"project_type"`
+	Header      string                     `json:"header"`
+	Extra       map[string]json.RawMessage `json:"-"`
 }
 
-// License represents a Modrinth license tag.
+// License represents a Modrinth license tag, or the license of a project.
 type License struct {
-	ID   string `json:"short"`
+	ID    string                     `json:"short"`
+	Name  string                     `json:"name"`
+	URL   string                     `json:"link,omitempty"`
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// projectForm records that the license was decoded from "id" and "url",
+	// so MarshalJSON writes those names back.
+	projectForm bool
+}
+
+// projectLicense is the form of License used in projects.
+type projectLicense struct {
+	ID   string `json:"id"`
 	Name string `json:"name"`
-	URL  string `json:"link,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// UnmarshalJSON decodes both license tags and project licenses, which name
+// the ID and URL fields "id" and "url" instead of "short" and "link".
+func (l *License) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Short string `json:"short"`
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Link  string `json:"link"`
+		URL   string `json:"url"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	extra, err := unknownFields(data, knownFields(reflect.TypeOf(raw)))
+	if err != nil {
+		return err
+	}
+	*l = License{ID: raw.Short, Name: raw.Name, URL: raw.Link, Extra: extra}
+	if l.ID == "" && raw.ID != "" {
+		l.ID = raw.ID
+		l.projectForm = true
+	}
+	if l.URL == "" {
+		l.URL = raw.URL
+	}
+	return nil
+}
+
+// MarshalJSON encodes the License together with the fields in Extra. A
+// license decoded from a project keeps the "id" and "url" names.
+func (l License) MarshalJSON() ([]byte, error) {
+	if l.projectForm {
+		return encodeExtra(projectLicense{l.ID, l.Name, l.URL}, l.Extra)
+	}
+	type plain License
+	return encodeExtra(plain(l), l.Extra)
 }
 
 // GameVersion represents a Modrinth game version tag.
 type GameVersion struct {
-	Version     string `json:"version"`
-	VersionType string `json:"version_type"`
-	Date        string `json:"date"`
-	Major       bool   `json:"major"`
+	Version     string                     `json:"version"`
+	VersionType string                     `json:"version_type"`
+	Date        string                     `json:"date"`
+	Major       bool                       `json:"major"`
+	Extra       map[string]json.RawMessage `json:"-"`
 }
 
 // Loader represents a Modrinth loader tag.
 type Loader struct {
-	Icon                  string   `json:"icon"`
-	Name                  string   `json:"name"`
-	SupportedProjectTypes []string `json:"supported_project_types"`
+	Icon                  string                     `json:"icon"`
+	Name                  string                     `json:"name"`
+	SupportedProjectTypes []string                   `json:"supported_project_types"`
+	Extra                 map[string]json.RawMessage `json:"-"`
+}
+
+// DonationPlatform represents a Modrinth donation platform tag.
+type DonationPlatform struct {
+	Short string                     `json:"short"`
+	Name  string                     `json:"name"`
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Statistics represents statistics about the Modrinth instance.
+type Statistics struct {
+	Projects int                        `json:"projects"`
+	Versions int                        `json:"versions"`
+	Files    int                        `json:"files"`
+	Authors  int                        `json:"authors"`
+	Extra    map[string]json.RawMessage `json:"-"`
 }
 
 // User represents a Modrinth user.
 type User struct {
-	ID        string `json:"id"`
-	Username  string `json:"username"`
-	Name      string `json:"name,omitempty"`
-	Email     string `json:"email,omitempty"`
-	Bio       string `json:"bio,omitempty"`
-	AvatarURL string `json:"avatar_url"`
-	Created   string `json:"created"`
-	Role      string `json:"role"`
-	Badges    int    `json:"badges"`
-	GithubID  int    `json:"github_id,omitempty"`
+	ID        string                     `json:"id"`
+	Username  string                     `json:"username"`
+	Name      string                     `json:"name,omitempty"`
+	Email     string                     `json:"email,omitempty"`
+	Bio       string                     `json:"bio,omitempty"`
+	AvatarURL string                     `json:"avatar_url"`
+	Created   string                     `json:"created"`
+	Role      string                     `json:"role"`
+	Badges    int                        `json:"badges"`
+	GithubID  int                        `json:"github_id,omitempty"`
+	Extra     map[string]json.RawMessage `json:"-"`
 }
 
 // TeamMember represents a member of a project team.
 type TeamMember struct {
-	TeamID       string  `json:"team_id"`
-	User         User    `json:"user"`
-	Role         string  `json:"role"`
-	Permissions  int     `json:"permissions"`
-	Accepted     bool    `json:"accepted"`
-	PayoutsShare float64 `json:"payouts_share,omitempty"`
-	Ordering     int     `json:"ordering"`
+	TeamID       string                     `json:"team_id"`
+	User         User                       `json:"user"`
+	Role         string                     `json:"role"`
+	Permissions  int                        `json:"permissions"`
+	Accepted     bool                       `json:"accepted"`
+	PayoutsShare float64                    `json:"payouts_share,omitempty"`
+	Ordering     int                        `json:"ordering"`
+	Extra        map[string]json.RawMessage `json:"-"`
 }
 
 // Collection represents a Modrinth collection (inferred from API usage).
 type Collection struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Projects    []string `json:"projects"`
-	IconURL     string   `json:"icon_url,omitempty"`
+	ID          string                     `json:"id"`
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Projects    []string                   `json:"projects"`
+	IconURL     string                     `json:"icon_url,omitempty"`
+	Extra       map[string]json.RawMessage `json:"-"`
+}
+
+// PayoutBalance represents the payout balance of the authenticated user.
+type PayoutBalance struct {
+	Available         Amount                     `json:"available"`
+	Pending           Amount                     `json:"pending"`
+	WithdrawnLifetime Amount                     `json:"withdrawn_lifetime"`
+	WithdrawnYTD      Amount                     `json:"withdrawn_ytd"`
+	Dates             map[string]Amount          `json:"dates,omitempty"`
+	Extra             map[string]json.RawMessage `json:"-"`
+}
+
+// Payout represents a withdrawal of the authenticated user.
+type Payout struct {
+	ID            string                     `json:"id"`
+	UserID        string                     `json:"user_id"`
+	Status        string                     `json:"status"`
+	Created       string                     `json:"created"`
+	Amount        Amount                     `json:"amount"`
+	Fee           Amount                     `json:"fee,omitempty"`
+	Method        string                     `json:"method,omitempty"`
+	MethodAddress string                     `json:"method_address,omitempty"`
+	PlatformID    string                     `json:"platform_id,omitempty"`
+	Extra         map[string]json.RawMessage `json:"-"`
 }
 
 // ModrinthV2Client is a client for the Modrinth V2 API.
@@ -232,4 +379,6 @@ type ModrinthV2Client struct {
 	baseURL    string
 	headers    map[string]string
 	httpClient *http.Client
+	flights    flightGroup
+	strict     bool
 }
@@ -0,0 +1,171 @@
+package modrinth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// ErrNoMatchingVersion is returned when no project version satisfies the requested filters.
+var ErrNoMatchingVersion = errors.New("no matching project version")
+
+// VersionPolicy decides which version types are acceptable when picking a version.
+type VersionPolicy int
+
+const (
+	// ReleaseOnly accepts only release versions.
+	ReleaseOnly VersionPolicy = iota
+	// AllowBeta accepts release and beta versions.
+	AllowBeta
+	// AllowAlpha accepts release, beta and alpha versions.
+	AllowAlpha
+)
+
+// Accepts reports whether the version type is acceptable under the policy.
+func (p VersionPolicy) Accepts(versionType string) bool {
+	switch versionType {
+	case "release":
+		return true
+	case "beta":
+		return p >= AllowBeta
+	case "alpha":
+		return p >= AllowAlpha
+	}
+	return false
+}
+
+func (o GetProjectVersionsOptions) query() (url.Values, error) {
+	v := url.Values{}
+	if len(o.Loaders) > 0 {
+		b, err := json.Marshal(o.Loaders)
+		if err != nil {
+			return nil, err
+		}
+		v.Add("loaders", string(b))
+	}
+	if len(o.GameVersions) > 0 {
+		b, err := json.Marshal(o.GameVersions)
+		if err != nil {
+			return nil, err
+		}
+		v.Add("game_versions", string(b))
+	}
+	if o.Featured != nil {
+		v.Add("featured", fmt.Sprintf("%t", *o.Featured))
+	}
+	if o.IncludeChangelog != nil {
+		v.Add("include_changelog", fmt.Sprintf("%t", *o.IncludeChangelog))
+	}
+	return v, nil
+}
+
+// Matches reports whether the version passes the client-side filters of the options.
+func (o GetProjectVersionsOptions) Matches(version *ProjectVersion) bool {
+	if len(o.VersionTypes) > 0 && !slices.Contains(o.VersionTypes, version.VersionType) {
+		return false
+	}
+	if !o.PublishedAfter.IsZero() || !o.PublishedBefore.IsZero() {
+		published, err := version.Published()
+		if err != nil {
+			return false
+		}
+		if !o.PublishedAfter.IsZero() && published.Before(o.PublishedAfter) {
+			return false
+		}
+		if !o.PublishedBefore.IsZero() && !published.Before(o.PublishedBefore) {
+			return false
+		}
+	}
+	if o.PrimaryFileOnly && version.PrimaryFile() == nil {
+		return false
+	}
+	return true
+}
+
+// FilterVersions returns the versions that pass the client-side filters of the options.
+func FilterVersions(versions []ProjectVersion, options GetProjectVersionsOptions) []ProjectVersion {
+	if len(options.VersionTypes) == 0 && options.PublishedAfter.IsZero() && options.PublishedBefore.IsZero() && !options.PrimaryFileOnly {
+		return versions
+	}
+	result := make([]ProjectVersion, 0, len(versions))
+	for i := range versions {
+		if options.Matches(&versions[i]) {
+			result = append(result, versions[i])
+		}
+	}
+	return result
+}
+
+// Published parses the publish date of the version.
+func (v *ProjectVersion) Published() (time.Time, error) {
+	return time.Parse(time.RFC3339, v.DatePublished)
+}
+
+// PrimaryFile returns the file flagged as primary, or nil if there is none.
+func (v *ProjectVersion) PrimaryFile() *VersionFile {
+	for i := range v.Files {
+		if v.Files[i].Primary {
+			return &v.Files[i]
+		}
+	}
+	return nil
+}
+
+// SelectBestVersion picks the newest version that supports the loader and game
+// version and is acceptable under the policy. An empty loader or game version
+// matches any. It returns nil if no version qualifies.
+func SelectBestVersion(versions []ProjectVersion, loader, gameVersion string, policy VersionPolicy) *ProjectVersion {
+	var best *ProjectVersion
+	var bestTime time.Time
+	for i := range versions {
+		v := &versions[i]
+		if !policy.Accepts(v.VersionType) {
+			continue
+		}
+		if loader != "" && !slices.Contains(v.Loaders, loader) {
+			continue
+		}
+		if gameVersion != "" && !slices.Contains(v.GameVersions, gameVersion) {
+			continue
+		}
+		published, err := v.Published()
+		if err != nil {
+			// Keep the API order, which lists the newest versions first.
+			if best == nil {
+				best = v
+			}
+			continue
+		}
+		if best == nil || published.After(bestTime) {
+			best = v
+			bestTime = published
+		}
+	}
+	return best
+}
+
+// BestVersion fetches the versions of a project and picks the newest one that
+// supports the loader and game version and is acceptable under the policy.
+// It returns ErrNoMatchingVersion if no version qualifies.
+func (c *ModrinthV2Client) BestVersion(ctx context.Context, projectID, loader, gameVersion string, policy VersionPolicy) (*ProjectVersion, error) {
+	options := GetProjectVersionsOptions{IncludeChangelog: new(bool)}
+	if loader != "" {
+		options.Loaders = []string{loader}
+	}
+	if gameVersion != "" {
+		options.GameVersions = []string{gameVersion}
+	}
+	versions, err := c.GetProjectVersions(ctx, projectID, options)
+	if err != nil {
+		return nil, err
+	}
+	best := SelectBestVersion(versions, loader, gameVersion, policy)
+	if best == nil {
+		return nil, ErrNoMatchingVersion
+	}
+	return best, nil
+}
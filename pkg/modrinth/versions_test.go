@@ -0,0 +1,152 @@
+package modrinth_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+var testVersions = []modrinth.ProjectVersion{
+	{
+		ID:            "alpha",
+		VersionType:   "alpha",
+		DatePublished: "2024-04-01T00:00:00Z",
+		Loaders:       []string{"fabric"},
+		GameVersions:  []string{"1.20.4"},
+	},
+	{
+		ID:            "beta",
+		VersionType:   "beta",
+		DatePublished: "2024-03-01T00:00:00Z",
+		Loaders:       []string{"fabric"},
+		GameVersions:  []string{"1.20.4"},
+		Files:         []modrinth.VersionFile{{Filename: "beta.jar", Primary: true}},
+	},
+	{
+		ID:            "release-forge",
+		VersionType:   "release",
+		DatePublished: "2024-02-15T00:00:00Z",
+		Loaders:       []string{"forge"},
+		GameVersions:  []string{"1.20.4"},
+	},
+	{
+		ID:            "release",
+		VersionType:   "release",
+		DatePublished: "2024-02-01T00:00:00Z",
+		Loaders:       []string{"fabric", "quilt"},
+		GameVersions:  []string{"1.20.1", "1.20.4"},
+		Files:         []modrinth.VersionFile{{Filename: "release.jar", Primary: true}},
+	},
+}
+
+func TestFilterVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  modrinth.GetProjectVersionsOptions
+		expected []string
+	}{
+		{
+			name:     "No client-side filters",
+			options:  modrinth.GetProjectVersionsOptions{},
+			expected: []string{"alpha", "beta", "release-forge", "release"},
+		},
+		{
+			name:     "Version types",
+			options:  modrinth.GetProjectVersionsOptions{VersionTypes: []string{"release", "beta"}},
+			expected: []string{"beta", "release-forge", "release"},
+		},
+		{
+			name: "Date range",
+			options: modrinth.GetProjectVersionsOptions{
+				PublishedAfter:  time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC),
+				PublishedBefore: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			},
+			expected: []string{"beta", "release-forge"},
+		},
+		{
+			name:     "Primary file only",
+			options:  modrinth.GetProjectVersionsOptions{PrimaryFileOnly: true},
+			expected: []string{"beta", "release"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := modrinth.FilterVersions(testVersions, tt.options)
+			var ids []string
+			for _, v := range result {
+				ids = append(ids, v.ID)
+			}
+			if len(ids) != len(tt.expected) {
+				t.Fatalf("FilterVersions() = %v, want %v", ids, tt.expected)
+			}
+			for i := range ids {
+				if ids[i] != tt.expected[i] {
+					t.Fatalf("FilterVersions() = %v, want %v", ids, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectBestVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		loader      string
+		gameVersion string
+		policy      modrinth.VersionPolicy
+		expected    string
+	}{
+		{name: "Release only", loader: "fabric", gameVersion: "1.20.4", policy: modrinth.ReleaseOnly, expected: "release"},
+		{name: "Allow beta", loader: "fabric", gameVersion: "1.20.4", policy: modrinth.AllowBeta, expected: "beta"},
+		{name: "Allow alpha", loader: "fabric", gameVersion: "1.20.4", policy: modrinth.AllowAlpha, expected: "alpha"},
+		{name: "Other loader", loader: "forge", gameVersion: "1.20.4", policy: modrinth.AllowAlpha, expected: "release-forge"},
+		{name: "Any loader", loader: "", gameVersion: "1.20.4", policy: modrinth.ReleaseOnly, expected: "release-forge"},
+		{name: "No match", loader: "neoforge", gameVersion: "1.20.4", policy: modrinth.AllowAlpha, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := modrinth.SelectBestVersion(testVersions, tt.loader, tt.gameVersion, tt.policy)
+			var id string
+			if result != nil {
+				id = result.ID
+			}
+			if id != tt.expected {
+				t.Errorf("SelectBestVersion() = %q, want %q", id, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBestVersion(t *testing.T) {
+	client, server := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("include_changelog") != "false" {
+			t.Errorf("include_changelog = %q, want false", q.Get("include_changelog"))
+		}
+		if q.Get("game_versions") != `["1.20.4"]` {
+			t.Errorf("game_versions = %q, want [\"1.20.4\"]", q.Get("game_versions"))
+		}
+		json.NewEncoder(w).Encode(testVersions)
+	})
+	defer server.Close()
+
+	result, err := client.BestVersion(context.Background(), "project", "fabric", "1.20.4", modrinth.AllowBeta)
+	if err != nil {
+		t.Fatalf("BestVersion() error = %v", err)
+	}
+	if result.ID != "beta" {
+		t.Errorf("BestVersion() = %s, want beta", result.ID)
+	}
+
+	_, err = client.BestVersion(context.Background(), "project", "neoforge", "1.20.4", modrinth.AllowAlpha)
+	if !errors.Is(err, modrinth.ErrNoMatchingVersion) {
+		t.Errorf("BestVersion() error = %v, want ErrNoMatchingVersion", err)
+	}
+}
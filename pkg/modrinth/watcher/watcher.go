@@ -0,0 +1,292 @@
+// Package watcher polls followed projects or collections and reports new versions.
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+)
+
+const (
+	defaultInterval   = 30 * time.Minute
+	defaultMaxBackoff = 6 * time.Hour
+)
+
+// Source lists the projects to watch.
+type Source interface {
+	Projects(ctx context.Context) ([]modrinth.Project, error)
+}
+
+// SourceFunc adapts a function to a Source.
+type SourceFunc func(ctx context.Context) ([]modrinth.Project, error)
+
+// Projects calls f.
+func (f SourceFunc) Projects(ctx context.Context) ([]modrinth.Project, error) {
+	return f(ctx)
+}
+
+// FollowedProjects watches the projects followed by a user.
+func FollowedProjects(client *modrinth.ModrinthV2Client, userID string) Source {
+	return SourceFunc(func(ctx context.Context) ([]modrinth.Project, error) {
+		return client.GetUserFollowedProjects(ctx, userID)
+	})
+}
+
+// CollectionProjects watches the projects of a collection.
+func CollectionProjects(client *modrinth.ModrinthV2Client, collectionID string) Source {
+	return SourceFunc(func(ctx context.Context) ([]modrinth.Project, error) {
+		collection, err := client.GetCollection(ctx, collectionID)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetProjectsBatch(ctx, collection.Projects, modrinth.BatchOptions{})
+	})
+}
+
+// Event reports a new version of a watched project.
+type Event struct {
+	Project modrinth.Project
+	Version modrinth.ProjectVersion
+	// Changelog is the Markdown changelog of the version.
+	Changelog string
+}
+
+// Options configures a watcher.
+type Options struct {
+	// Interval is the time between polls. Defaults to 30 minutes.
+	Interval time.Duration
+	// MaxBackoff caps the delay after repeated failures. Defaults to 6 hours.
+	MaxBackoff time.Duration
+	// StatePath is the file the cursor is persisted to. Empty keeps it in memory.
+	StatePath string
+	// Loaders keeps only versions supporting one of the loaders. Empty keeps all.
+	Loaders []string
+	// GameVersions keeps only versions supporting one of the game versions. Empty keeps all.
+	GameVersions []string
+	// VersionTypes keeps only versions of the given types. Empty keeps all.
+	VersionTypes []string
+	// OnEvent receives events instead of the Events channel when set.
+	OnEvent func(Event)
+	// OnError receives poll errors. Polling continues after an error.
+	OnError func(error)
+}
+
+// State is the persisted cursor of a watcher.
+type State struct {
+	Projects map[string]ProjectState `json:"projects"`
+}
+
+// ProjectState is the last seen state of a project.
+type ProjectState struct {
+	Updated  string   `json:"updated"`
+	Versions []string `json:"versions"`
+}
+
+// Watcher polls a source and emits an event for each new version.
+// The first poll of a project records its versions without emitting events.
+type Watcher struct {
+	client  *modrinth.ModrinthV2Client
+	source  Source
+	options Options
+	events  chan Event
+
+	mu    sync.Mutex
+	state *State
+}
+
+// New creates a watcher. The persisted state is loaded from Options.StatePath if present.
+func New(client *modrinth.ModrinthV2Client, source Source, options Options) (*Watcher, error) {
+	if options.Interval <= 0 {
+		options.Interval = defaultInterval
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = defaultMaxBackoff
+	}
+	w := &Watcher{
+		client:  client,
+		source:  source,
+		options: options,
+		events:  make(chan Event, 16),
+		state:   &State{Projects: make(map[string]ProjectState)},
+	}
+	if options.StatePath != "" {
+		b, err := os.ReadFile(options.StatePath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(b, w.state); err != nil {
+				return nil, err
+			}
+			if w.state.Projects == nil {
+				w.state.Projects = make(map[string]ProjectState)
+			}
+		}
+	}
+	return w, nil
+}
+
+// Events returns the channel events are delivered to when no OnEvent callback is set.
+// It is closed when Run returns.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Run polls until the context is canceled. After a failed poll the next poll
+// is delayed exponentially, up to Options.MaxBackoff. The cursor only advances
+// once every event of a poll is delivered, so events that were not delivered
+// before Run returned are emitted again by the next poll.
+func (w *Watcher) Run(ctx context.Context) error {
+	defer close(w.events)
+	delay := time.Duration(0)
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		events, state, err := w.Poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if w.options.OnError != nil {
+				w.options.OnError(err)
+			}
+			failures++
+			delay = backoff(w.options.Interval, w.options.MaxBackoff, failures)
+			continue
+		}
+		failures = 0
+		delay = w.options.Interval
+
+		for _, e := range events {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if w.options.OnEvent != nil {
+				w.options.OnEvent(e)
+				continue
+			}
+			select {
+			case w.events <- e:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err := w.Commit(state); err != nil && w.options.OnError != nil {
+			w.options.OnError(err)
+		}
+	}
+}
+
+func backoff(interval, maxDelay time.Duration, failures int) time.Duration {
+	delay := interval
+	for range failures {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	return delay
+}
+
+// Poll checks the source once and returns the new versions along with the
+// state that records them as seen. The cursor is left unchanged until the
+// state is passed to Commit, so callers commit it after handling the events.
+func (w *Watcher) Poll(ctx context.Context) ([]Event, *State, error) {
+	projects, err := w.source.Projects(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var newIDs []string
+	byID := make(map[string]modrinth.Project, len(projects))
+	next := make(map[string]ProjectState, len(projects))
+	for _, p := range projects {
+		byID[p.ID] = p
+		next[p.ID] = ProjectState{Updated: p.Updated, Versions: p.Versions}
+		previous, ok := w.state.Projects[p.ID]
+		if !ok || (previous.Updated == p.Updated && len(previous.Versions) == len(p.Versions)) {
+			continue
+		}
+		for _, id := range p.Versions {
+			if !slices.Contains(previous.Versions, id) {
+				newIDs = append(newIDs, id)
+			}
+		}
+	}
+
+	var events []Event
+	if len(newIDs) > 0 {
+		versions, err := w.client.GetProjectVersionsByIDBatch(ctx, newIDs, modrinth.BatchOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, v := range versions {
+			if !w.accepts(&v) {
+				continue
+			}
+			events = append(events, Event{Project: byID[v.ProjectID], Version: v, Changelog: v.Changelog})
+		}
+		slices.SortStableFunc(events, func(a, b Event) int {
+			ta, _ := a.Version.Published()
+			tb, _ := b.Version.Published()
+			return ta.Compare(tb)
+		})
+	}
+
+	return events, &State{Projects: next}, nil
+}
+
+// Commit makes the state returned by Poll the cursor and persists it.
+func (w *Watcher) Commit(state *State) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.state = state
+	return w.save()
+}
+
+func (w *Watcher) accepts(v *modrinth.ProjectVersion) bool {
+	if len(w.options.VersionTypes) > 0 && !slices.Contains(w.options.VersionTypes, v.VersionType) {
+		return false
+	}
+	if len(w.options.Loaders) > 0 && !slices.ContainsFunc(v.Loaders, func(l string) bool { return slices.Contains(w.options.Loaders, l) }) {
+		return false
+	}
+	if len(w.options.GameVersions) > 0 && !slices.ContainsFunc(v.GameVersions, func(g string) bool { return slices.Contains(w.options.GameVersions, g) }) {
+		return false
+	}
+	return true
+}
+
+func (w *Watcher) save() error {
+	if w.options.StatePath == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(w.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(w.options.StatePath), 0o755); err != nil {
+		return err
+	}
+	tmp := w.options.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, w.options.StatePath)
+}
@@ -0,0 +1,187 @@
+package watcher_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth"
+	"github.com/Voxelum/minecraft-launcher-core/pkg/modrinth/watcher"
+)
+
+type fakeModrinth struct {
+	mu       sync.Mutex
+	projects []modrinth.Project
+	versions map[string]modrinth.ProjectVersion
+}
+
+func (f *fakeModrinth) addVersion(v modrinth.ProjectVersion) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.versions[v.ID] = v
+	for i := range f.projects {
+		if f.projects[i].ID == v.ProjectID {
+			f.projects[i].Versions = append(f.projects[i].Versions, v.ID)
+			f.projects[i].Updated = v.DatePublished
+		}
+	}
+}
+
+func setup(t *testing.T) (*fakeModrinth, *modrinth.ModrinthV2Client) {
+	f := &fakeModrinth{
+		projects: []modrinth.Project{{ID: "AAAA", Slug: "mod-a"}},
+		versions: map[string]modrinth.ProjectVersion{},
+	}
+	f.addVersion(modrinth.ProjectVersion{ID: "v1", ProjectID: "AAAA", VersionType: "release", Loaders: []string{"fabric"}, DatePublished: "2024-01-01T00:00:00Z"})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v2/user/{id}/follows", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		json.NewEncoder(w).Encode(f.projects)
+	})
+	mux.HandleFunc("GET /v2/versions", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		var ids []string
+		json.Unmarshal([]byte(r.URL.Query().Get("ids")), &ids)
+		result := []modrinth.ProjectVersion{}
+		for _, id := range ids {
+			result = append(result, f.versions[id])
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return f, modrinth.NewModrinthV2Client(modrinth.WithBaseURL(server.URL))
+}
+
+func TestPoll(t *testing.T) {
+	f, client := setup(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	options := watcher.Options{StatePath: statePath, Loaders: []string{"fabric"}}
+	w, err := watcher.New(client, watcher.FollowedProjects(client, "user"), options)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	events, state, err := w.Poll(ctx)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("first Poll() = %v, %v, want no events", events, err)
+	}
+	if err := w.Commit(state); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	f.addVersion(modrinth.ProjectVersion{ID: "v2-forge", ProjectID: "AAAA", VersionType: "release", Loaders: []string{"forge"}, DatePublished: "2024-02-01T00:00:00Z"})
+	f.addVersion(modrinth.ProjectVersion{ID: "v2", ProjectID: "AAAA", VersionType: "release", Loaders: []string{"fabric"}, DatePublished: "2024-02-01T00:00:00Z", Changelog: "Fixed things"})
+
+	// A new watcher resumes from the persisted cursor.
+	w, err = watcher.New(client, watcher.FollowedProjects(client, "user"), options)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	events, state, err = w.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if len(events) != 1 || events[0].Version.ID != "v2" || events[0].Changelog != "Fixed things" || events[0].Project.ID != "AAAA" {
+		t.Errorf("Poll() = %+v, want one event for v2", events)
+	}
+	if err := w.Commit(state); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	events, _, err = w.Poll(ctx)
+	if err != nil || len(events) != 0 {
+		t.Errorf("Poll() without changes = %v, %v, want no events", events, err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	f, client := setup(t)
+	w, err := watcher.New(client, watcher.FollowedProjects(client, "user"), watcher.Options{Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_, state, err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if err := w.Commit(state); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	f.addVersion(modrinth.ProjectVersion{ID: "v2", ProjectID: "AAAA", DatePublished: "2024-02-01T00:00:00Z"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go w.Run(ctx)
+
+	select {
+	case e := <-w.Events():
+		if e.Version.ID != "v2" {
+			t.Errorf("event version = %s, want v2", e.Version.ID)
+		}
+	case <-ctx.Done():
+		t.Fatal("no event received")
+	}
+	cancel()
+	for range w.Events() {
+	}
+}
+
+func TestRunCanceledDuringDelivery(t *testing.T) {
+	f, client := setup(t)
+	options := watcher.Options{Interval: time.Hour, StatePath: filepath.Join(t.TempDir(), "state.json")}
+	w, err := watcher.New(client, watcher.FollowedProjects(client, "user"), options)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_, state, err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if err := w.Commit(state); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	f.addVersion(modrinth.ProjectVersion{ID: "v2", ProjectID: "AAAA", DatePublished: "2024-02-01T00:00:00Z"})
+	f.addVersion(modrinth.ProjectVersion{ID: "v3", ProjectID: "AAAA", DatePublished: "2024-03-01T00:00:00Z"})
+
+	// The consumer goes away after the first event.
+	ctx, cancel := context.WithCancel(context.Background())
+	var delivered []string
+	options.OnEvent = func(e watcher.Event) {
+		delivered = append(delivered, e.Version.ID)
+		cancel()
+	}
+	w, err = watcher.New(client, watcher.FollowedProjects(client, "user"), options)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := w.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+	if len(delivered) != 1 {
+		t.Fatalf("delivered %v, want one event before the cancel", delivered)
+	}
+
+	// Neither the watcher nor a restarted one lost the undelivered events.
+	for _, name := range []string{"same", "restarted"} {
+		if name == "restarted" {
+			if w, err = watcher.New(client, watcher.FollowedProjects(client, "user"), options); err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+		}
+		events, _, err := w.Poll(context.Background())
+		if err != nil || len(events) != 2 || events[0].Version.ID != "v2" || events[1].Version.ID != "v3" {
+			t.Errorf("%s watcher Poll() = %+v, %v, want v2 and v3 again", name, events, err)
+		}
+	}
+}